@@ -22,7 +22,8 @@ func (f RouteInitFunc) InitRoute(r *gin.Engine) {
 }
 
 func NewGinEngine(authService *auth.AuthService, authController *controller.AuthController,
-	taskController *controller.TaskController, logger *zap.SugaredLogger) *gin.Engine {
+	taskController *controller.TaskController, scheduleController *controller.ScheduleController,
+	secretController *controller.SecretController, logger *zap.SugaredLogger) *gin.Engine {
 	r := gin.New()
 	r.MaxMultipartMemory = 100 << 20
 	r.Use(ginzap.Ginzap(logger.Desugar(), time.RFC3339, true))
@@ -31,7 +32,8 @@ func NewGinEngine(authService *auth.AuthService, authController *controller.Auth
 	// Swagger文档路由
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	InitRoutes(r, InitAuthRoute(authController), InitTaskRoute(authService, taskController))
+	InitRoutes(r, InitAuthRoute(authController), InitTaskRoute(authService, taskController), InitScheduleRoute(authService, scheduleController),
+		InitSecretRoute(authService, secretController))
 	return r
 }
 
@@ -58,13 +60,52 @@ func InitTaskRoute(authService *auth.AuthService, taskController *controller.Tas
 		g.Use(auth.AuthMiddleware(authService))
 		{
 			g.GET("/list", taskController.ListTasks)
+			g.POST("", taskController.AddTask)
+			g.PUT("/:id", taskController.UpdateTask)
+			g.GET("/job_types", taskController.ListJobTypes)
 			g.POST("/upload_file", taskController.UploadFile)
 			g.DELETE("/delete_file", taskController.DeleteFile)
 			g.GET("/list_files", taskController.ListFiles)
 			g.POST("/add_shell_task", taskController.AddShellTask)
+			g.PUT("/update_shell_task", taskController.UpdateShellTask)
 			g.DELETE("/delete", taskController.DeleteTask)
 			g.GET("/logs", taskController.ListTaskLog)
 			g.POST("/run", taskController.RunTask)
+			g.GET("/runs/:run_id", taskController.GetRun)
+			g.GET("/runs/:run_id/stream", taskController.StreamRun)
+			g.GET("/runs/:run_id/output", taskController.DownloadOutput)
+			g.GET("/workflow_runs/:workflow_run_id", taskController.GetWorkflowRun)
+			g.POST("/pause", taskController.PauseTask)
+			g.POST("/resume", taskController.ResumeTask)
+			g.GET("/running", taskController.ListRunning)
+			g.POST("/cancel_run", taskController.CancelRun)
+		}
+	})
+}
+
+func InitScheduleRoute(authService *auth.AuthService, scheduleController *controller.ScheduleController) RouteIniter {
+	return RouteInitFunc(func(r *gin.Engine) {
+		// 健康检查不需要认证
+		r.GET("/health", scheduleController.Health)
+
+		g := r.Group("/api/v1/schedules")
+		// need auth
+		g.Use(auth.AuthMiddleware(authService))
+		{
+			g.POST("/preview", scheduleController.PreviewSchedule)
+		}
+	})
+}
+
+func InitSecretRoute(authService *auth.AuthService, secretController *controller.SecretController) RouteIniter {
+	return RouteInitFunc(func(r *gin.Engine) {
+		g := r.Group("/api/v1/secrets")
+		// need auth
+		g.Use(auth.AuthMiddleware(authService))
+		{
+			g.PUT("", secretController.SaveSecret)
+			g.GET("", secretController.ListSecrets)
+			g.DELETE("", secretController.DeleteSecret)
 		}
 	})
 }
@@ -28,6 +28,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 内置时区数据库，运行环境缺少 tzdata 时任务时区仍可用
 
 	"github.com/chencheng8888/GoDo/api"
 	"github.com/chencheng8888/GoDo/config"
@@ -74,11 +75,13 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// 先排空调度器，期间 HTTP 服务继续提供健康检查和执行查询
+	app.s.Stop()
+
 	const timeout = 5 * time.Second
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	app.a.Close(ctx)
-	app.s.Stop()
 }
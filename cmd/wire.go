@@ -12,6 +12,7 @@ import (
 	"github.com/chencheng8888/GoDo/pkg/id_generator"
 	"github.com/chencheng8888/GoDo/pkg/log"
 	"github.com/chencheng8888/GoDo/scheduler"
+	"github.com/chencheng8888/GoDo/secret"
 	"github.com/google/wire"
 )
 
@@ -26,5 +27,6 @@ func WireNewApp(*config.Config) (*App, error) {
 		log.ProviderSet,
 		scheduler.ProviderSet,
 		id_generator.ProviderSet,
+		secret.ProviderSet,
 	))
 }
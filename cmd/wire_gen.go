@@ -15,6 +15,7 @@ import (
 	"github.com/chencheng8888/GoDo/pkg/id_generator"
 	"github.com/chencheng8888/GoDo/pkg/log"
 	"github.com/chencheng8888/GoDo/scheduler"
+	"github.com/chencheng8888/GoDo/secret"
 )
 
 import (
@@ -41,22 +42,42 @@ func WireNewApp(configConfig *config.Config) (*App, error) {
 	authController := controller.NewAuthController(authService)
 	scheduleConfig := config.GetScheduleConfig(configConfig)
 	logMiddleware := scheduler.NewLogMiddleware(sugaredLogger)
+	taskIDGenerator := id_generator.NewTaskIDGenerator()
 	taskLogDao := dao.NewTaskLogDao(db)
-	taskLogMiddleware := scheduler.NewTaskLogMiddleware(sugaredLogger, taskLogDao)
+	taskLogWriter, err := scheduler.NewTaskLogWriter(scheduleConfig, taskLogDao, sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	taskLogMiddleware := scheduler.NewTaskLogMiddleware(sugaredLogger, taskLogWriter)
+	runRegistry := scheduler.NewRunRegistry()
+	secretConfig := config.GetSecretConfig(configConfig)
+	userSecretDao := dao.NewUserSecretDao(db)
+	store, err := secret.NewStore(secretConfig, userSecretDao)
+	if err != nil {
+		return nil, err
+	}
+	secretMiddleware := scheduler.NewSecretMiddleware(sugaredLogger, store)
+	limitMiddleware := scheduler.NewLimitMiddleware(scheduleConfig, userDao, sugaredLogger)
+	runAsMapper, err := scheduler.NewRunAsMapper(scheduleConfig)
+	if err != nil {
+		return nil, err
+	}
+	runAsMiddleware := scheduler.NewRunAsMiddleware(runAsMapper)
 	taskInfoDao := dao.NewTaskInfoDao(db)
-	cronScheduler, err := scheduler.NewCronScheduler(scheduleConfig, logMiddleware, taskLogMiddleware, taskInfoDao, sugaredLogger)
+	cronScheduler, err := scheduler.NewCronScheduler(scheduleConfig, logMiddleware, taskLogMiddleware, secretMiddleware, limitMiddleware, runAsMiddleware, taskLogWriter, runRegistry, taskInfoDao, taskLogDao, taskIDGenerator, sugaredLogger)
 	if err != nil {
 		return nil, err
 	}
 	schedulerScheduler := scheduler.NewScheduler(cronScheduler)
-	taskIDGenerator := id_generator.NewTaskIDGenerator()
 	fileConfig := config.GetFileConfig(configConfig)
 	userFileDao := dao.NewUserFileDao(db)
-	taskController, err := controller.NewTaskController(schedulerScheduler, taskIDGenerator, scheduleConfig, fileConfig, userDao, userFileDao, taskLogDao, taskInfoDao, sugaredLogger)
+	taskController, err := controller.NewTaskController(schedulerScheduler, taskIDGenerator, scheduleConfig, fileConfig, userDao, userFileDao, taskLogDao, taskInfoDao, store, runAsMapper, sugaredLogger)
 	if err != nil {
 		return nil, err
 	}
-	engine := api.NewGinEngine(authService, authController, taskController, sugaredLogger)
+	scheduleController := controller.NewScheduleController(schedulerScheduler)
+	secretController := controller.NewSecretController(store, sugaredLogger)
+	engine := api.NewGinEngine(authService, authController, taskController, scheduleController, secretController, sugaredLogger)
 	apiAPI := api.NewAPI(serverConfig, engine, sugaredLogger)
 	app := NewApp(apiAPI, schedulerScheduler)
 	return app, nil
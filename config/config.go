@@ -6,7 +6,7 @@ import (
 )
 
 var (
-	ProviderSet = wire.NewSet(GetServerConfig, GetLogConfig, GetScheduleConfig, GetDBConfig, GetJwtConfig, GetFileConfig)
+	ProviderSet = wire.NewSet(GetServerConfig, GetLogConfig, GetScheduleConfig, GetDBConfig, GetJwtConfig, GetFileConfig, GetSecretConfig)
 )
 
 func LoadConfig(configPath string) *Config {
@@ -7,6 +7,7 @@ type Config struct {
 	DB       *DBConfig       `mapstructure:"db"`
 	Jwt      *JwtConfig      `mapstructure:"jwt"`
 	File     *FileConfig     `mapstructure:"file"`
+	Secret   *SecretConfig   `mapstructure:"secret"`
 }
 
 type ServerConfig struct {
@@ -30,9 +31,45 @@ type ScheduleConfig struct {
 	WorkDir        string `mapstructure:"work_dir"`        // 任务工作目录
 	GoroutinesSize int    `mapstructure:"goroutines_size"` // 任务执行协程池大小
 
+	KillGracePeriod   int    `mapstructure:"kill_grace_period"`  // 任务超时或取消时，发送 SIGTERM 后等待多久再发送 SIGKILL，单位:秒，不配置时为10秒
+	DrainTimeout      int    `mapstructure:"drain_timeout"`      // 服务停止时等待正在执行的任务结束的最长时间，超时后终止并记为 interrupted，单位:秒，不配置时为30秒
+	HeartbeatInterval int    `mapstructure:"heartbeat_interval"` // 执行中的任务日志更新心跳时间的间隔，单位:秒，不配置时为30秒
+	CgroupRoot        string `mapstructure:"cgroup_root"`        // 任务使用的 cgroup v2 目录(如 /sys/fs/cgroup/godo)，为空时只通过 rlimit 限制资源，仅 Linux 有效
+
+	RunAs *RunAsConfig `mapstructure:"run_as"` // 任务进程使用的系统用户，为空时以服务自身的用户运行
+
+	OutputLimit int    `mapstructure:"output_limit"` // 单次执行标准输出和错误输出各自在内存和任务日志中保留的大小，超出时只保留开头和结尾，单位:KB，不配置时为32KB
+	OutputDir   string `mapstructure:"output_dir"`   // 输出超过上限时完整输出压缩保存的目录，为空时超出部分直接丢弃
+
+	OutputRetention int `mapstructure:"output_retention"` // 完整输出文件的保留时间，超过后被定期删除，单位:天，不配置时为7天
+
+	TaskLog *TaskLogConfig `mapstructure:"task_log"` // 任务日志异步写入配置
+
 	MaxTaskNum int `mapstructure:"max_task_num"` // 最大任务数量限制
 }
 
+// TaskLogConfig 任务日志先进入内存队列，再由后台协程批量写入数据库
+type TaskLogConfig struct {
+	QueueSize     int    `mapstructure:"queue_size"`     // 队列长度，队列满时直接写入本地暂存文件，不配置时为1024
+	BatchSize     int    `mapstructure:"batch_size"`     // 每批写入的最大条数，不配置时为100
+	FlushInterval int    `mapstructure:"flush_interval"` // 写入数据库的间隔，单位:毫秒，不配置时为1000
+	JournalPath   string `mapstructure:"journal_path"`   // 数据库不可用时暂存任务日志的本地文件，数据库恢复后重新写入，不配置时为 ./logs/task_log.journal
+}
+
+// RunAsConfig GoDo 用户到系统用户的映射，需要服务以 root 或具有 CAP_SETUID/CAP_SETGID/CAP_CHOWN 权限运行
+type RunAsConfig struct {
+	Default *OSUserConfig  `mapstructure:"default"` // 未单独映射的用户共用的沙箱用户，为空时这些用户的任务以服务自身的用户运行
+	Users   []OSUserConfig `mapstructure:"users"`   // 按用户映射的系统用户
+}
+
+// OSUserConfig 系统用户，设置 os_user 时从系统中查询 uid/gid，否则使用 uid/gid
+type OSUserConfig struct {
+	Name   string `mapstructure:"name"`    // GoDo 用户名，仅 users 中需要
+	OSUser string `mapstructure:"os_user"` // 系统用户名
+	UID    uint32 `mapstructure:"uid"`
+	GID    uint32 `mapstructure:"gid"`
+}
+
 type DBConfig struct {
 	Addr string `mapstructure:"addr"` // 数据库地址
 }
@@ -47,6 +84,10 @@ type FileConfig struct {
 	SingleFileSizeLimit int `mapstructure:"single_file_size_limit"` // 上传单个文件大小限制,单位:MB
 }
 
+type SecretConfig struct {
+	Key string `mapstructure:"key"` // 加密用户密钥的 AES 密钥(base64编码，长度16/24/32字节)
+}
+
 func GetScheduleConfig(cf *Config) *ScheduleConfig {
 	return cf.Schedule
 }
@@ -70,3 +111,7 @@ func GetJwtConfig(cf *Config) *JwtConfig {
 func GetFileConfig(cf *Config) *FileConfig {
 	return cf.File
 }
+
+func GetSecretConfig(cf *Config) *SecretConfig {
+	return cf.Secret
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chencheng8888/GoDo/pkg/response"
+	"github.com/chencheng8888/GoDo/scheduler"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPreviewCount = 5
+)
+
+type ScheduleController struct {
+	scheduler scheduler.Scheduler
+}
+
+func NewScheduleController(s scheduler.Scheduler) *ScheduleController {
+	return &ScheduleController{
+		scheduler: s,
+	}
+}
+
+// PreviewScheduleRequest 调度预览请求
+// @Description 调度预览的请求参数
+type PreviewScheduleRequest struct {
+	ScheduledTime string `json:"scheduled_time" binding:"required" example:"0 0 2 * * *"`              // 调度描述，格式与创建任务时相同
+	ScheduleType  string `json:"schedule_type" binding:"omitempty,oneof=cron at every" example:"cron"` // 调度方式(cron/at/every)，默认cron
+	TimeZone      string `json:"time_zone" binding:"omitempty,timezone" example:"Asia/Shanghai"`       // IANA时区名，默认服务器本地时区
+	Count         int    `json:"count" binding:"omitempty,min=1,max=50" example:"5"`                   // 返回的触发次数，默认5
+}
+
+// PreviewScheduleResponseData 调度预览响应数据
+// @Description 接下来的触发时间和调度解释
+type PreviewScheduleResponseData struct {
+	NextRunTimes []time.Time                   `json:"next_run_times"` // 接下来的触发时间
+	Description  scheduler.ScheduleDescription `json:"description"`    // 中英文解释
+}
+
+// PreviewSchedule 调度预览
+// @Summary 调度预览
+// @Description 按调度器当前的解析配置(是否启用秒级)解析调度描述，返回接下来的触发时间和中英文解释
+// @Tags 调度
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PreviewScheduleRequest true "调度预览参数"
+// @Success 200 {object} response.Response{data=PreviewScheduleResponseData} "success"
+// @Failure 400 {object} response.Response "invalid request"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
+// @Router /api/v1/schedules/preview [post]
+func (sc *ScheduleController) PreviewSchedule(c *gin.Context) {
+	var req PreviewScheduleRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	if req.Count == 0 {
+		req.Count = defaultPreviewCount
+	}
+
+	times, desc, err := sc.scheduler.PreviewSchedule(req.ScheduleType, req.ScheduledTime, req.TimeZone, req.Count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(PreviewScheduleResponseData{
+		NextRunTimes: times,
+		Description:  desc,
+	}))
+}
+
+// Health 健康检查
+// @Summary 健康检查
+// @Description 返回调度器状态和等待或正在执行的任务数；服务停止时进入 draining 状态并返回 503，负载均衡应不再转发请求
+// @Tags 调度
+// @Produce json
+// @Success 200 {object} response.Response{data=scheduler.HealthInfo} "ok"
+// @Failure 503 {object} response.Response{data=scheduler.HealthInfo} "draining"
+// @Router /health [get]
+func (sc *ScheduleController) Health(c *gin.Context) {
+	info := sc.scheduler.Health()
+	code := http.StatusOK
+	if info.Status == scheduler.HealthStatusDraining {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, response.Success(info))
+}
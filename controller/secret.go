@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chencheng8888/GoDo/auth"
+	"github.com/chencheng8888/GoDo/dao"
+	"github.com/chencheng8888/GoDo/pkg/response"
+	"github.com/chencheng8888/GoDo/secret"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SecretController struct {
+	store *secret.Store
+
+	log *zap.SugaredLogger
+}
+
+func NewSecretController(store *secret.Store, log *zap.SugaredLogger) *SecretController {
+	return &SecretController{
+		store: store,
+		log:   log,
+	}
+}
+
+// checkEnabled 未配置密钥时密钥接口不可用
+func (sc *SecretController) checkEnabled(c *gin.Context) bool {
+	if !sc.store.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, response.Error(response.SecretNotConfiguredCode, fmt.Sprintf("%s:%s", response.SecretNotConfiguredMsg, "set secret.key in the config file to enable secrets")))
+		return false
+	}
+	return true
+}
+
+// SaveSecretRequest 保存密钥请求
+// @Description 保存密钥的请求参数，同名密钥已存在时覆盖
+type SaveSecretRequest struct {
+	Name  string `json:"name" binding:"required,max=128" example:"db_password"` // 密钥名称，只能包含字母、数字、下划线、点和中划线，任务中通过 ${secret:NAME} 引用
+	Value string `json:"value" binding:"required,max=8192" example:"p@ssw0rd"`  // 密钥值，加密后保存，不会再通过接口返回
+}
+
+// SaveSecret 保存密钥
+// @Summary 保存密钥
+// @Description 加密保存当前用户的密钥，同名密钥已存在时覆盖
+// @Tags 密钥管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SaveSecretRequest true "密钥参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "invalid request"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Failure 500 {object} response.Response "save secret failed"
+// @Failure 503 {object} response.Response "secret store not configured"
+// @Router /api/v1/secrets [put]
+func (sc *SecretController) SaveSecret(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+	if !sc.checkEnabled(c) {
+		return
+	}
+
+	var req SaveSecretRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+	if err := secret.ValidateName(req.Name); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	if err := sc.store.Set(name, req.Name, req.Value); err != nil {
+		sc.log.Errorf("save secret %s for user %s failed: %v", req.Name, name, err)
+		c.JSON(http.StatusInternalServerError, response.Error(response.SaveSecretFailedCode, fmt.Sprintf("%s:%s", response.SaveSecretFailedMsg, err.Error())))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(nil))
+}
+
+// SecretResponse 密钥信息，不包含密钥值
+// @Description 密钥信息
+type SecretResponse struct {
+	Name      string    `json:"name" example:"db_password"` // 密钥名称
+	CreatedAt time.Time `json:"created_at"`                 // 创建时间
+	UpdatedAt time.Time `json:"updated_at"`                 // 最后修改时间
+}
+
+// ListSecretsResponseData 密钥列表响应数据
+// @Description 当前用户的密钥列表
+type ListSecretsResponseData struct {
+	Secrets []SecretResponse `json:"secrets"` // 密钥列表
+}
+
+// ListSecrets 列出密钥
+// @Summary 列出密钥
+// @Description 列出当前用户保存的密钥名称，不返回密钥值
+// @Tags 密钥管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=ListSecretsResponseData} "success"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Failure 500 {object} response.Response "search failed"
+// @Failure 503 {object} response.Response "secret store not configured"
+// @Router /api/v1/secrets [get]
+func (sc *SecretController) ListSecrets(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+	if !sc.checkEnabled(c) {
+		return
+	}
+
+	secrets, err := sc.store.List(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(response.SearchFailedCode, response.SearchFailedMsg))
+		return
+	}
+
+	res := make([]SecretResponse, 0, len(secrets))
+	for _, s := range secrets {
+		res = append(res, SecretResponse{Name: s.Name, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt})
+	}
+	c.JSON(http.StatusOK, response.Success(ListSecretsResponseData{Secrets: res}))
+}
+
+// DeleteSecretRequest 删除密钥请求
+// @Description 删除密钥的请求参数
+type DeleteSecretRequest struct {
+	Name string `json:"name" binding:"required" example:"db_password"` // 密钥名称
+}
+
+// DeleteSecret 删除密钥
+// @Summary 删除密钥
+// @Description 删除当前用户的密钥，引用该密钥的任务在下次执行时会失败
+// @Tags 密钥管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DeleteSecretRequest true "删除密钥参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "invalid request / user secret not found"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Failure 500 {object} response.Response "delete secret failed"
+// @Failure 503 {object} response.Response "secret store not configured"
+// @Router /api/v1/secrets [delete]
+func (sc *SecretController) DeleteSecret(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+	if !sc.checkEnabled(c) {
+		return
+	}
+
+	var req DeleteSecretRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	if err := sc.store.Delete(name, req.Name); err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, dao.UserSecretNotFoundErr) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, response.Error(response.DeleteSecretFailedCode, fmt.Sprintf("%s:%s", response.DeleteSecretFailedMsg, err.Error())))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(nil))
+}
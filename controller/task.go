@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/chencheng8888/GoDo/auth"
@@ -9,11 +9,14 @@ import (
 	"github.com/chencheng8888/GoDo/dao/model"
 	"github.com/chencheng8888/GoDo/pkg/id_generator"
 	"github.com/chencheng8888/GoDo/scheduler"
+	"github.com/chencheng8888/GoDo/secret"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/chencheng8888/GoDo/config"
@@ -39,8 +42,14 @@ type TaskController struct {
 
 	taskInfoDao *dao.TaskInfoDao
 
+	secretStore *secret.Store
+
+	runAs *scheduler.RunAsMapper
+
 	workDir string
 
+	outputDir string
+
 	log *zap.SugaredLogger
 
 	fileNumberLimit     int
@@ -49,7 +58,7 @@ type TaskController struct {
 }
 
 func NewTaskController(s scheduler.Scheduler, generator id_generator.TaskIDGenerator, cf *config.ScheduleConfig, fileConf *config.FileConfig,
-	userDao *dao.UserDao, userFileDao *dao.UserFileDao, taskLogDao *dao.TaskLogDao, taskInfoDao *dao.TaskInfoDao, log *zap.SugaredLogger) (*TaskController, error) {
+	userDao *dao.UserDao, userFileDao *dao.UserFileDao, taskLogDao *dao.TaskLogDao, taskInfoDao *dao.TaskInfoDao, secretStore *secret.Store, runAs *scheduler.RunAsMapper, log *zap.SugaredLogger) (*TaskController, error) {
 	err := pkg.CreateDirIfNotExist(cf.WorkDir)
 	if err != nil {
 		return nil, err
@@ -58,6 +67,7 @@ func NewTaskController(s scheduler.Scheduler, generator id_generator.TaskIDGener
 	return &TaskController{
 		scheduler:           s,
 		workDir:             cf.WorkDir,
+		outputDir:           cf.OutputDir,
 		generator:           generator,
 		fileNumberLimit:     fileConf.NumberLimit,
 		singleFileSizeLimit: fileConf.SingleFileSizeLimit,
@@ -65,33 +75,91 @@ func NewTaskController(s scheduler.Scheduler, generator id_generator.TaskIDGener
 		userFileDao:         userFileDao,
 		taskLogDao:          taskLogDao,
 		taskInfoDao:         taskInfoDao,
+		secretStore:         secretStore,
+		runAs:               runAs,
 		log:                 log,
 		maxTaskNum:          cf.MaxTaskNum,
 	}, nil
 }
 
+// checkJobLimits 校验任务的资源限制没有超过用户的默认限制
+func checkJobLimits(user model.User, job scheduler.Job) error {
+	limited, ok := job.(scheduler.LimitedJob)
+	if !ok {
+		return nil
+	}
+	return scheduler.ValidateLimits(limited.GetLimits(), user.Limits)
+}
+
+// checkJobSecrets 校验任务引用的密钥都已保存，避免任务执行时才发现密钥缺失
+func (tc *TaskController) checkJobSecrets(userName string, job scheduler.Job) error {
+	consumer, ok := job.(scheduler.SecretConsumer)
+	if !ok {
+		return nil
+	}
+	missing, err := tc.secretStore.Missing(userName, consumer.SecretRefs())
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("secret %s not found", strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// findTaskLog 查询已结束执行的任务日志，日志刚写入队列尚未保存到数据库时从调度器中查询
+func (tc *TaskController) findTaskLog(userName, runID string) (*model.TaskLog, error) {
+	if taskLog, ok := tc.scheduler.GetUnsavedLog(userName, runID); ok {
+		return &taskLog, nil
+	}
+	return tc.taskLogDao.FindByRunId(userName, runID)
+}
+
 // TaskResponse 用于API响应的任务结构体
 // @Description 任务信息响应结构
 type TaskResponse struct {
 	ID            string `json:"id" example:"12345"`                                                 // 任务ID
 	TaskName      string `json:"task_name" example:"daily-backup"`                                   // 任务名称
-	ScheduledTime string `json:"scheduled_time" example:"0 2 * * * *"`                               // Cron表达式
+	ScheduledTime string `json:"scheduled_time" example:"0 2 * * * *"`                               // 调度描述
+	ScheduleType  string `json:"schedule_type" example:"cron"`                                       // 调度方式(cron/at/every)
+	TimeZone      string `json:"time_zone" example:"Asia/Shanghai"`                                  // 时区，为空表示服务器本地时区
 	OwnerName     string `json:"owner_name" example:"admin"`                                         // 任务拥有者
 	Description   string `json:"description" example:"每日数据备份任务"`                                     // 任务描述
 	JobType       string `json:"job_type" example:"shell"`                                           // 任务类型
 	Job           string `json:"job" example:"{\"command\":\"/bin/bash\",\"args\":[\"backup.sh\"]}"` // 任务详情(JSON格式)
+	Status        string `json:"status" example:"active"`                                            // 任务状态(active/paused/completed)
+
+	RetryPolicy       *RetryPolicyRequest `json:"retry_policy,omitempty"`              // 重试策略，时间单位与请求一致为秒
+	ConcurrencyPolicy string              `json:"concurrency_policy" example:"forbid"` // 重叠执行策略
+	RerunInterrupted  bool                `json:"rerun_interrupted" example:"false"`   // 服务异常退出导致执行中断时，重启后是否重新执行一次
+	MisfirePolicy     string              `json:"misfire_policy" example:"run_once"`   // 错过触发时间时的处理策略
+	MisfireLimit      int                 `json:"misfire_limit" example:"0"`           // run_all 策略最多补执行的次数，0 表示默认值
+	UpstreamTaskIDs   []string            `json:"upstream_task_ids,omitempty"`         // 上游任务ID，非空时由工作流触发
+	TriggerRule       string              `json:"trigger_rule" example:"all_success"`  // 上游完成后触发本任务的规则
+	NextRunTime       *time.Time          `json:"next_run_time,omitempty"`             // 下一次触发时间(任务时区)，暂停或已完成的任务没有
+	PrevRunTime       *time.Time          `json:"prev_run_time,omitempty"`             // 上一次触发时间(任务时区)，本次启动后尚未触发时没有
 }
 
 // TaskToResponse 将scheduler.Task转换为TaskResponse
 func TaskToResponse(task scheduler.Task) TaskResponse {
 	return TaskResponse{
-		ID:            task.GetID(),
-		TaskName:      task.GetTaskName(),
-		ScheduledTime: task.GetScheduledTime(),
-		OwnerName:     task.GetOwnerName(),
-		Description:   task.GetDescription(),
-		JobType:       task.GetJob().Type(),
-		Job:           task.GetJob().Content(),
+		ID:                task.GetID(),
+		TaskName:          task.GetTaskName(),
+		ScheduledTime:     task.GetScheduledTime(),
+		ScheduleType:      task.GetScheduleType(),
+		TimeZone:          task.GetTimeZone(),
+		OwnerName:         task.GetOwnerName(),
+		Description:       task.GetDescription(),
+		JobType:           task.GetJob().Type(),
+		Job:               task.GetJob().Content(),
+		Status:            task.GetStatus(),
+		RetryPolicy:       toRetryPolicyRequest(task.GetRetryPolicy()),
+		ConcurrencyPolicy: task.GetConcurrencyPolicy(),
+		RerunInterrupted:  task.GetRerunInterrupted(),
+		MisfirePolicy:     task.GetMisfirePolicy(),
+		MisfireLimit:      task.GetMisfireLimit(),
+		UpstreamTaskIDs:   task.GetUpstreams(),
+		TriggerRule:       task.GetTriggerRule(),
 	}
 }
 
@@ -128,7 +196,15 @@ func (tc *TaskController) ListTasks(c *gin.Context) {
 	// 转换为响应结构体
 	var taskResponses []TaskResponse
 	for _, task := range tasks {
-		taskResponses = append(taskResponses, TaskToResponse(task))
+		resp := TaskToResponse(task)
+		next, prev := tc.scheduler.FireTimes(task)
+		if !next.IsZero() {
+			resp.NextRunTime = &next
+		}
+		if !prev.IsZero() {
+			resp.PrevRunTime = &prev
+		}
+		taskResponses = append(taskResponses, resp)
 	}
 
 	c.JSON(http.StatusOK, response.Success(ListTaskResponseData{Tasks: taskResponses}))
@@ -185,7 +261,7 @@ func (tc *TaskController) UploadFile(c *gin.Context) {
 	fileName := fmt.Sprintf("%d-%s", time.Now().UnixMilli(), filepath.Base(file.Filename))
 
 	dir := filepath.Join(tc.workDir, name)
-	err = pkg.CreateDirIfNotExist(dir)
+	err = tc.runAs.PrepareDir(name, dir)
 	if err != nil {
 		tc.log.Errorf("create dir failed : dir is %v, err: %v", dir, err)
 		c.JSON(http.StatusInternalServerError, response.Error(response.FileSaveFailedCode, response.FileSaveFailedMsg))
@@ -205,6 +281,14 @@ func (tc *TaskController) UploadFile(c *gin.Context) {
 		return
 	}
 
+	// 文件交给用户映射的系统用户，任务以该用户运行时才能读写
+	err = tc.runAs.Chown(name, savePath)
+	if err != nil {
+		tc.log.Errorf("chown file failed : file is %v, err: %v", savePath, err)
+		c.JSON(http.StatusInternalServerError, response.Error(response.FileSaveFailedCode, response.FileSaveFailedMsg))
+		return
+	}
+
 	err = tc.userFileDao.AddUserFileRecord(name, fileName, file.Size)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Error(response.FileSaveFailedCode, response.FileSaveFailedMsg))
@@ -319,16 +403,90 @@ func (tc *TaskController) ListFiles(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Success(ListFilesResponseData{Files: files}))
 }
 
-// AddShellTaskRequest 添加Shell任务请求
-// @Description 添加Shell任务的请求参数
-type AddShellTaskRequest struct {
-	TaskName      string   `json:"task_name" binding:"required" example:"daily-backup"`          // 任务名称
-	Description   string   `json:"description" binding:"required" example:"每日数据备份任务"`            // 任务描述
-	ScheduledTime string   `json:"scheduled_time" binding:"required,cron" example:"0 2 * * * *"` // Cron表达式(支持秒级)
-	Command       string   `json:"command" binding:"required" example:"./backup.sh"`             // 执行命令
-	Args          []string `json:"args" binding:"omitempty" example:"--full"`                    // 命令参数
-	UseShell      bool     `json:"use_shell" binding:"omitempty" example:"true"`                 // 是否使用Shell
-	Timeout       int      `json:"timeout" binding:"required,max=7200,gt=0" example:"1800"`      // 超时时间(秒)，最大2小时
+// TaskOptionsRequest 任务共用参数
+// @Description 各类任务共用的名称、调度方式以及重试、并发、错过触发和工作流等策略
+type TaskOptionsRequest struct {
+	TaskName          string   `json:"task_name" binding:"required" example:"daily-backup"`                                          // 任务名称
+	Description       string   `json:"description" binding:"required" example:"每日数据备份任务"`                                            // 任务描述
+	ScheduledTime     string   `json:"scheduled_time" binding:"required_without=UpstreamTaskIDs" example:"0 2 * * * *"`              // 调度描述：cron表达式(支持秒级)、at的执行时间(如2026-11-01 03:00:00)或every的间隔(如90s、1h+15m)，设置上游任务时忽略
+	ScheduleType      string   `json:"schedule_type" binding:"omitempty,oneof=cron at every" example:"cron"`                         // 调度方式(cron/at/every)，默认cron
+	TimeZone          string   `json:"time_zone" binding:"omitempty,timezone" example:"Asia/Shanghai"`                               // IANA时区名，默认服务器本地时区
+	ConcurrencyPolicy string   `json:"concurrency_policy" binding:"omitempty,oneof=allow forbid replace queue" example:"forbid"`     // 重叠执行策略(allow/forbid/replace/queue)，默认allow
+	RerunInterrupted  bool     `json:"rerun_interrupted" binding:"omitempty" example:"false"`                                        // 服务异常退出导致执行中断时，重启后是否重新执行一次，默认false
+	MisfirePolicy     string   `json:"misfire_policy" binding:"omitempty,oneof=skip run_once run_all" example:"run_once"`            // 服务停止或任务暂停期间错过触发时间时的处理策略(skip/run_once/run_all)，默认skip
+	MisfireLimit      int      `json:"misfire_limit" binding:"omitempty,min=1,max=100" example:"10"`                                 // run_all 策略最多补执行的次数，默认10
+	UpstreamTaskIDs   []string `json:"upstream_task_ids" binding:"omitempty,max=20,dive,required" example:"task_1699123456789"`      // 上游任务ID，设置后任务不再定时触发，而是在上游结束后由工作流触发
+	TriggerRule       string   `json:"trigger_rule" binding:"omitempty,oneof=all_success any_failed all_done" example:"all_success"` // 触发规则(all_success/any_failed/all_done)，默认all_success
+
+	Retry *RetryPolicyRequest `json:"retry"` // 重试策略，不传表示不重试
+}
+
+// validateSchedule 按调度方式校验调度描述，依赖上游的任务不需要调度描述
+func (r *TaskOptionsRequest) validateSchedule() error {
+	if len(r.UpstreamTaskIDs) > 0 {
+		return nil
+	}
+	return scheduler.ValidateSchedule(r.ScheduleType, r.ScheduledTime, r.TimeZone)
+}
+
+// buildTask 按共用参数构造执行 job 的任务
+func (r *TaskOptionsRequest) buildTask(taskID, ownerName string, job scheduler.Job) scheduler.Task {
+	return scheduler.NewTask(taskID, r.TaskName, ownerName, r.ScheduledTime, r.Description, job,
+		scheduler.WithRetryPolicy(r.Retry.toRetryPolicy()), scheduler.WithConcurrencyPolicy(r.ConcurrencyPolicy),
+		scheduler.WithRerunInterrupted(r.RerunInterrupted), scheduler.WithMisfirePolicy(r.MisfirePolicy, r.MisfireLimit),
+		scheduler.WithScheduleType(r.ScheduleType), scheduler.WithTimeZone(r.TimeZone),
+		scheduler.WithUpstreams(r.UpstreamTaskIDs), scheduler.WithTriggerRule(r.TriggerRule))
+}
+
+// RetryPolicyRequest 重试策略参数
+// @Description 任务失败后的重试策略
+type RetryPolicyRequest struct {
+	MaxAttempts      int    `json:"max_attempts" binding:"required,min=1,max=10" example:"3"`                  // 最大尝试次数(含首次执行)
+	Backoff          string `json:"backoff" binding:"omitempty,oneof=fixed exponential" example:"exponential"` // 退避方式
+	Interval         int    `json:"interval" binding:"omitempty,min=0,max=3600" example:"10"`                  // 首次重试前的等待时间(秒)
+	MaxInterval      int    `json:"max_interval" binding:"omitempty,min=0,max=86400" example:"300"`            // 指数退避的最大等待时间(秒)
+	RetryOnExitCodes []int  `json:"retry_on_exit_codes" binding:"omitempty" example:"1"`                       // 仅在这些退出码时重试
+	RetryOnTimeout   bool   `json:"retry_on_timeout" binding:"omitempty" example:"true"`                       // 超时时重试
+}
+
+// ResourceLimitsRequest 资源限制参数
+// @Description 任务进程的资源限制，仅在 Linux 上生效，不能超过用户的默认限制，字段为0表示使用用户默认值
+type ResourceLimitsRequest struct {
+	CPUSeconds     uint64 `json:"cpu_seconds" binding:"omitempty" example:"600"`       // CPU 时间(秒)
+	AddressSpaceMB uint64 `json:"address_space_mb" binding:"omitempty" example:"2048"` // 虚拟地址空间(MB)，启用 cgroup 时同时作为内存上限
+	OpenFiles      uint64 `json:"open_files" binding:"omitempty" example:"1024"`       // 打开文件数
+	Processes      uint64 `json:"processes" binding:"omitempty" example:"64"`          // 进程数
+	FileSizeMB     uint64 `json:"file_size_mb" binding:"omitempty" example:"512"`      // 可写入的单个文件大小(MB)
+}
+
+// toRetryPolicy 将请求参数转换为调度器使用的重试策略
+func (r *RetryPolicyRequest) toRetryPolicy() *scheduler.RetryPolicy {
+	if r == nil {
+		return nil
+	}
+	return &scheduler.RetryPolicy{
+		MaxAttempts:      r.MaxAttempts,
+		Backoff:          r.Backoff,
+		Interval:         time.Duration(r.Interval) * time.Second,
+		MaxInterval:      time.Duration(r.MaxInterval) * time.Second,
+		RetryOnExitCodes: r.RetryOnExitCodes,
+		RetryOnTimeout:   r.RetryOnTimeout,
+	}
+}
+
+// toRetryPolicyRequest 将调度器的重试策略转换为以秒为单位的参数形式，用于响应
+func toRetryPolicyRequest(p *scheduler.RetryPolicy) *RetryPolicyRequest {
+	if p == nil {
+		return nil
+	}
+	return &RetryPolicyRequest{
+		MaxAttempts:      p.MaxAttempts,
+		Backoff:          p.Backoff,
+		Interval:         int(p.Interval / time.Second),
+		MaxInterval:      int(p.MaxInterval / time.Second),
+		RetryOnExitCodes: p.RetryOnExitCodes,
+		RetryOnTimeout:   p.RetryOnTimeout,
+	}
 }
 
 // AddShellTaskResponseData 添加Shell任务响应数据
@@ -337,65 +495,282 @@ type AddShellTaskResponseData struct {
 	TaskId string `json:"task_id" example:"12345"` // 新创建的任务ID
 }
 
-// AddShellTask 添加Shell任务
-// @Summary 添加Shell任务
-// @Description 创建一个新的Shell任务，支持定时执行，任务所有者从JWT token中获取
-// @Tags 任务管理
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param request body AddShellTaskRequest true "任务创建参数"
-// @Success 200 {object} response.Response{data=AddShellTaskResponseData} "success"
-// @Failure 400 {object} response.Response "Bad request: invalid request"
-// @Failure 401 {object} response.Response "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer <token>; Invalid or expired token"
-// @Failure 500 {object} response.Response "search failed"
-// @Router /api/v1/tasks/add_shell_task [post]
-func (tc *TaskController) AddShellTask(c *gin.Context) {
+// taskUser 获取发起请求的用户，失败时写入响应并返回 false
+func (tc *TaskController) taskUser(c *gin.Context) (model.User, bool) {
 	name, ok := auth.GetUsernameFromContext(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
-		return
+		return model.User{}, false
 	}
 
 	user, err := tc.userDao.GetUser(name)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
-		return
+		return model.User{}, false
 	}
+	return user, true
+}
 
-	cnt, err := tc.taskInfoDao.CountTaskByUserName(name)
+// checkTaskQuota 校验用户的任务数没有达到上限，失败时写入响应并返回 false
+func (tc *TaskController) checkTaskQuota(c *gin.Context, userName string) bool {
+	cnt, err := tc.taskInfoDao.CountTaskByUserName(userName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Error(response.SearchFailedCode, response.SearchFailedMsg))
-		return
+		return false
 	}
 
 	if cnt+1 > int64(tc.maxTaskNum) {
 		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, "the user has reached the maximum number of tasks allowed")))
-		return
+		return false
 	}
+	return true
+}
 
-	var req AddShellTaskRequest
-	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+// bindTaskRequest 绑定任务的创建或更新请求并校验调度描述，失败时写入响应并返回 false
+func bindTaskRequest(c *gin.Context, req interface{ validateSchedule() error }) bool {
+	if err := c.ShouldBindBodyWithJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return false
+	}
+
+	if err := req.validateSchedule(); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return false
+	}
+	return true
+}
+
+// saveTask 校验任务的资源限制和引用的密钥后添加或更新任务，并写入响应
+func (tc *TaskController) saveTask(c *gin.Context, user model.User, task scheduler.Task, update bool) {
+	if err := checkJobLimits(user, task.GetJob()); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+	if err := tc.checkJobSecrets(user.UserName, task.GetJob()); err != nil {
 		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
 		return
 	}
 
-	if !user.UseShell && req.UseShell {
-		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, "the user is not allowed to use shell to run commands")))
+	if update {
+		if err := tc.scheduler.UpdateTask(task); err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(response.UpdateTaskFailedCode, fmt.Sprintf("%s:%s", response.UpdateTaskFailedMsg, err.Error())))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(nil))
+		return
+	}
+
+	if err := tc.scheduler.AddTask(task); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
 		return
 	}
+	c.JSON(http.StatusOK, response.Success(AddShellTaskResponseData{TaskId: task.GetID()}))
+}
+
+// AddTaskRequest 添加任务请求
+// @Description 通用的任务创建参数，job 字段的结构由 job_type 决定，可通过 /api/v1/tasks/job_types 查询
+type AddTaskRequest struct {
+	TaskOptionsRequest
 
-	shellJob := scheduler.NewShellJob(req.UseShell, time.Duration(req.Timeout)*time.Second, tc.workDir, name, req.Command, req.Args...)
+	JobType string          `json:"job_type" binding:"required" example:"shell"` // 任务类型
+	Job     json.RawMessage `json:"job" binding:"required" swaggertype:"object"` // 任务详情，结构见对应任务类型的 JSON Schema
+}
 
-	taskID := tc.generator.Generate(TaskIDPrefix)
+// bindJob 按 job_type 将 job 字段交给对应的任务类型解析和校验
+func (r *AddTaskRequest) bindJob(workDir string, user model.User) (scheduler.Job, error) {
+	jobType, ok := scheduler.LookupJobType(r.JobType)
+	if !ok {
+		return nil, fmt.Errorf("job type %s unknown", r.JobType)
+	}
+	return jobType.Bind(scheduler.JobBindContext{WorkDir: workDir, UserName: user.UserName, UseShell: user.UseShell}, r.Job)
+}
 
-	task := scheduler.NewTask(taskID, req.TaskName, name, req.ScheduledTime, req.Description, shellJob)
-	err = tc.scheduler.AddTask(task)
+// saveTaskRequest 按 job_type 解析 job 字段构造任务后添加或更新任务，并写入响应
+func (tc *TaskController) saveTaskRequest(c *gin.Context, user model.User, taskID string, req *AddTaskRequest, update bool) {
+	job, err := req.bindJob(tc.workDir, user)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
 		return
 	}
-	c.JSON(http.StatusOK, response.Success(AddShellTaskResponseData{TaskId: task.GetID()}))
+
+	tc.saveTask(c, user, req.buildTask(taskID, user.UserName, job), update)
+}
+
+// AddTask 添加任务
+// @Summary 添加任务
+// @Description 按 job_type 将 job 字段交给对应的任务类型解析和校验后创建任务，支持所有已注册的任务类型
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddTaskRequest true "任务创建参数"
+// @Success 200 {object} response.Response{data=AddShellTaskResponseData} "success"
+// @Failure 400 {object} response.Response "Bad request: invalid request; job type unknown"
+// @Failure 401 {object} response.Response "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer <token>; Invalid or expired token"
+// @Failure 500 {object} response.Response "search failed"
+// @Router /api/v1/tasks [post]
+func (tc *TaskController) AddTask(c *gin.Context) {
+	user, ok := tc.taskUser(c)
+	if !ok || !tc.checkTaskQuota(c, user.UserName) {
+		return
+	}
+
+	var req AddTaskRequest
+	if !bindTaskRequest(c, &req) {
+		return
+	}
+
+	tc.saveTaskRequest(c, user, tc.generator.Generate(TaskIDPrefix), &req, false)
+}
+
+// UpdateTaskRequest 更新任务请求
+// @Description 通用的任务更新参数，所有字段整体替换，job_type 必须与任务原有的类型一致
+type UpdateTaskRequest struct {
+	AddTaskRequest
+}
+
+// UpdateTask 更新任务
+// @Summary 更新任务
+// @Description 按 job_type 解析 job 字段后原地替换任务的调度和内容，任务ID和已有日志保持不变，支持所有已注册的任务类型
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Param request body UpdateTaskRequest true "任务更新参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "Bad request: invalid request; job type unknown; update task failed"
+// @Failure 401 {object} response.Response "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer <token>; Invalid or expired token"
+// @Router /api/v1/tasks/{id} [put]
+func (tc *TaskController) UpdateTask(c *gin.Context) {
+	user, ok := tc.taskUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTaskRequest
+	if !bindTaskRequest(c, &req) {
+		return
+	}
+
+	tc.saveTaskRequest(c, user, c.Param("id"), &req.AddTaskRequest, true)
+}
+
+// JobTypeResponse 任务类型信息
+// @Description 已注册的任务类型及其 job 字段的 JSON Schema
+type JobTypeResponse struct {
+	JobType string          `json:"job_type" example:"shell"`    // 任务类型
+	Schema  json.RawMessage `json:"schema" swaggertype:"object"` // job 字段的 JSON Schema
+}
+
+// ListJobTypesResponseData 任务类型列表响应数据
+type ListJobTypesResponseData struct {
+	JobTypes []JobTypeResponse `json:"job_types"`
+}
+
+// ListJobTypes 查询任务类型
+// @Summary 查询任务类型
+// @Description 列出所有已注册的任务类型及创建任务时 job 字段的 JSON Schema
+// @Tags 任务管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=ListJobTypesResponseData} "success"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
+// @Router /api/v1/tasks/job_types [get]
+func (tc *TaskController) ListJobTypes(c *gin.Context) {
+	jobTypes := scheduler.ListJobTypes()
+	res := make([]JobTypeResponse, 0, len(jobTypes))
+	for _, jt := range jobTypes {
+		res = append(res, JobTypeResponse{JobType: jt.Name, Schema: json.RawMessage(jt.Schema)})
+	}
+	c.JSON(http.StatusOK, response.Success(ListJobTypesResponseData{JobTypes: res}))
+}
+
+// ShellJobRequest Shell任务的内容
+// @Description 旧版Shell任务接口中的任务内容，字段与 shell 任务类型的 job 字段一致，校验规则见 /api/v1/tasks/job_types
+type ShellJobRequest struct {
+	Command  string                 `json:"command" example:"./backup.sh"`                   // 执行命令
+	Args     []string               `json:"args" example:"--full"`                           // 命令参数
+	UseShell bool                   `json:"use_shell" example:"true"`                        // 是否使用Shell
+	Timeout  int                    `json:"timeout" example:"1800"`                          // 超时时间(秒)，最大2小时
+	Env      map[string]string      `json:"env" example:"DB_PASSWORD:${secret:db_password}"` // 环境变量，最多50个，值中可通过 ${secret:NAME} 引用密钥
+	Limits   *ResourceLimitsRequest `json:"limits"`                                          // 资源限制，不传表示使用用户的默认限制
+}
+
+// AddShellTaskRequest 添加Shell任务请求
+// @Description 添加Shell任务的请求参数
+type AddShellTaskRequest struct {
+	TaskOptionsRequest
+	ShellJobRequest
+}
+
+// toAddTaskRequest 将任务内容作为 job 字段转换为通用的任务创建参数，由 shell 任务类型统一校验
+func (r *AddShellTaskRequest) toAddTaskRequest() *AddTaskRequest {
+	job, _ := json.Marshal(r.ShellJobRequest)
+	return &AddTaskRequest{TaskOptionsRequest: r.TaskOptionsRequest, JobType: scheduler.ShellJobType, Job: job}
+}
+
+// AddShellTask 添加Shell任务
+// @Summary 添加Shell任务
+// @Description 创建一个新的Shell任务，支持定时执行，任务所有者从JWT token中获取。已废弃，请使用 POST /api/v1/tasks
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddShellTaskRequest true "任务创建参数"
+// @Success 200 {object} response.Response{data=AddShellTaskResponseData} "success"
+// @Failure 400 {object} response.Response "Bad request: invalid request"
+// @Failure 401 {object} response.Response "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer <token>; Invalid or expired token"
+// @Failure 500 {object} response.Response "search failed"
+// @Deprecated
+// @Router /api/v1/tasks/add_shell_task [post]
+func (tc *TaskController) AddShellTask(c *gin.Context) {
+	user, ok := tc.taskUser(c)
+	if !ok || !tc.checkTaskQuota(c, user.UserName) {
+		return
+	}
+
+	var req AddShellTaskRequest
+	if !bindTaskRequest(c, &req) {
+		return
+	}
+
+	tc.saveTaskRequest(c, user, tc.generator.Generate(TaskIDPrefix), req.toAddTaskRequest(), false)
+}
+
+// UpdateShellTaskRequest 更新Shell任务请求
+// @Description 更新Shell任务的请求参数，所有字段整体替换
+type UpdateShellTaskRequest struct {
+	TaskID string `json:"task_id" binding:"required" example:"12345"` // 任务ID
+
+	AddShellTaskRequest
+}
+
+// UpdateShellTask 更新Shell任务
+// @Summary 更新Shell任务
+// @Description 原地修改任务的名称、描述、Cron表达式和Shell参数，任务ID和已有日志保持不变。已废弃，请使用 PUT /api/v1/tasks/{id}
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateShellTaskRequest true "任务更新参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "Bad request: invalid request; update task failed"
+// @Failure 401 {object} response.Response "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer <token>; Invalid or expired token"
+// @Deprecated
+// @Router /api/v1/tasks/update_shell_task [put]
+func (tc *TaskController) UpdateShellTask(c *gin.Context) {
+	user, ok := tc.taskUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateShellTaskRequest
+	if !bindTaskRequest(c, &req) {
+		return
+	}
+
+	tc.saveTaskRequest(c, user, req.TaskID, req.toAddTaskRequest(), true)
 }
 
 // DeleteTaskRequest 删除任务请求
@@ -438,6 +813,84 @@ func (tc *TaskController) DeleteTask(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Success(nil))
 }
 
+// PauseTaskRequest 暂停任务请求
+// @Description 暂停任务的请求参数
+type PauseTaskRequest struct {
+	TaskID string `json:"task_id" binding:"required" example:"12345"` // 任务ID
+}
+
+// PauseTask 暂停任务
+// @Summary 暂停任务
+// @Description 暂停指定任务的调度，任务定义和日志保留，可通过恢复接口重新调度
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PauseTaskRequest true "暂停任务参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "invalid request / pause task failed"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Router /api/v1/tasks/pause [post]
+func (tc *TaskController) PauseTask(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	var req PauseTaskRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	err := tc.scheduler.PauseTask(name, req.TaskID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.PauseTaskFailedCode, fmt.Sprintf("%s:%s", response.PauseTaskFailedMsg, err.Error())))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(nil))
+}
+
+// ResumeTaskRequest 恢复任务请求
+// @Description 恢复任务的请求参数
+type ResumeTaskRequest struct {
+	TaskID string `json:"task_id" binding:"required" example:"12345"` // 任务ID
+}
+
+// ResumeTask 恢复任务
+// @Summary 恢复任务
+// @Description 恢复已暂停任务的调度
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ResumeTaskRequest true "恢复任务参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "invalid request / resume task failed"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Router /api/v1/tasks/resume [post]
+func (tc *TaskController) ResumeTask(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	var req ResumeTaskRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	err := tc.scheduler.ResumeTask(name, req.TaskID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.ResumeTaskFailedCode, fmt.Sprintf("%s:%s", response.ResumeTaskFailedMsg, err.Error())))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(nil))
+}
+
 // ListTaskLogRequest 查询任务日志请求
 type ListTaskLogRequest struct {
 	Page     int `form:"page" binding:"required,min=1" example:"1"`               // 页码
@@ -500,15 +953,21 @@ type RunTaskRequest struct {
 	TaskID string `form:"task_id" json:"task_id" binding:"required" example:"12345"`
 }
 
+// RunTaskResponseData 运行任务响应数据
+// @Description 手动触发任务后返回的运行ID
+type RunTaskResponseData struct {
+	RunID string `json:"run_id" example:"run_1699123456789"` // 运行ID，可用于查询执行状态
+}
+
 // RunTask 运行任务
 // @Summary 运行任务
-// @Description 运行任务
+// @Description 手动触发任务，任务提交到协程池异步执行，立即返回运行ID
 // @Tags 任务管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param task_id query string true "任务id"
-// @Success 200 {object} response.Response{data=nil} "success"
+// @Success 200 {object} response.Response{data=RunTaskResponseData} "success"
 // @Failure 400 {object} response.Response "Bad request"
 // @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
 // @Failure 500 {object} response.Response "search failed / task run failed"
@@ -544,15 +1003,342 @@ func (tc *TaskController) RunTask(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
+	runID, err := tc.scheduler.RunTask(task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(response.TaskRunFailedCode, fmt.Sprintf("%s:%s", response.TaskRunFailedMsg, err.Error())))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(RunTaskResponseData{RunID: runID}))
+}
+
+// GetRunResponseData 查询单次执行响应数据
+// @Description 单次执行的状态、输出和耗时
+type GetRunResponseData struct {
+	RunID         string     `json:"run_id" example:"run_1699123456789"`                   // 运行ID
+	ParentRunID   string     `json:"parent_run_id,omitempty" example:""`                   // 重试时指向首次执行的运行ID
+	TaskID        string     `json:"task_id" example:"task_1699123456789"`                 // 任务ID
+	TaskName      string     `json:"task_name" example:"daily-backup"`                     // 任务名称
+	Status        string     `json:"status" example:"succeeded"`                           // 执行状态(pending/running/succeeded/failed/timed_out/cancelled/panicked/skipped)
+	Trigger       string     `json:"trigger" example:"manual"`                             // 触发来源
+	Attempt       int        `json:"attempt" example:"1"`                                  // 第几次尝试
+	WorkflowRunID string     `json:"workflow_run_id,omitempty" example:"wf_1699123456789"` // 所属工作流运行ID
+	ExitCode      *int       `json:"exit_code,omitempty" example:"0"`                      // 进程退出码，执行结束后才有
+	Output        string     `json:"output" example:"Hello World"`                         // 标准输出，执行中时为最近的输出
+	ErrOutput     string     `json:"err_output" example:""`                                // 错误输出，执行中时为最近的输出
+	StartTime     time.Time  `json:"start_time"`                                           // 开始时间
+	EndTime       *time.Time `json:"end_time,omitempty"`                                   // 结束时间，执行结束后才有
+	Duration      string     `json:"duration" example:"1.5s"`                              // 已执行时长
+
+	Usage *model.ResourceUsage `json:"usage,omitempty"` // 资源使用统计，执行结束后才有
+
+	OutputFile    string `json:"output_file,omitempty"`     // 输出被截断时完整输出的文件名，可通过 /runs/{run_id}/output 下载
+	ErrOutputFile string `json:"err_output_file,omitempty"` // 错误输出被截断时完整输出的文件名
+}
+
+// GetRun 查询单次执行
+// @Summary 查询单次执行
+// @Description 根据运行ID查询执行状态、输出和耗时，可用于轮询手动触发的任务
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param run_id path string true "运行ID"
+// @Success 200 {object} response.Response{data=GetRunResponseData} "success"
+// @Failure 400 {object} response.Response "search failed: run not found"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
+// @Failure 500 {object} response.Response "search failed"
+// @Router /api/v1/tasks/runs/{run_id} [get]
+func (tc *TaskController) GetRun(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	runID := c.Param("run_id")
+
+	if running, ok := tc.scheduler.GetRunning(name, runID); ok {
+		c.JSON(http.StatusOK, response.Success(GetRunResponseData{
+			RunID:         running.RunID,
+			TaskID:        running.TaskID,
+			TaskName:      running.TaskName,
+			Status:        running.Status,
+			Trigger:       running.Trigger,
+			Attempt:       running.Attempt,
+			WorkflowRunID: running.WorkflowRunID,
+			Output:        running.Output,
+			ErrOutput:     running.ErrOutput,
+			StartTime:     running.StartTime,
+			Duration:      time.Since(running.StartTime).String(),
+		}))
+		return
+	}
+
+	taskLog, err := tc.findTaskLog(name, runID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, response.Error(response.SearchFailedCode, response.SearchFailedMsg))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(GetRunResponseData{
+		RunID:         taskLog.RunId,
+		ParentRunID:   taskLog.ParentRunId,
+		TaskID:        taskLog.TaskId,
+		TaskName:      taskLog.Name,
+		Status:        taskLog.Status,
+		Trigger:       taskLog.Trigger,
+		Attempt:       taskLog.Attempt,
+		WorkflowRunID: taskLog.WorkflowRunId,
+		ExitCode:      &taskLog.ExitCode,
+		Output:        taskLog.Output,
+		ErrOutput:     taskLog.ErrOutput,
+		StartTime:     taskLog.StartTime,
+		EndTime:       &taskLog.EndTime,
+		Duration:      taskLog.EndTime.Sub(taskLog.StartTime).String(),
+		Usage:         &taskLog.Usage,
+		OutputFile:    taskLog.OutputFile,
+		ErrOutputFile: taskLog.ErrOutputFile,
+	}))
+}
+
+// GetWorkflowRunResponseData 查询工作流运行响应数据
+// @Description 一次工作流运行中各节点的执行情况
+type GetWorkflowRunResponseData struct {
+	WorkflowRunID string                  `json:"workflow_run_id" example:"wf_1699123456789"` // 工作流运行ID
+	Running       []scheduler.RunningInfo `json:"running"`                                    // 等待中或执行中的节点
+	Logs          []model.TaskLog         `json:"logs"`                                       // 已结束节点的任务日志，按开始时间排序
+}
+
+// GetWorkflowRun 查询工作流运行
+// @Summary 查询工作流运行
+// @Description 根据工作流运行ID查询本次运行中各节点的状态和任务日志，被触发规则跳过的节点状态为 skipped
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param workflow_run_id path string true "工作流运行ID"
+// @Success 200 {object} response.Response{data=GetWorkflowRunResponseData} "success"
+// @Failure 400 {object} response.Response "search failed: workflow run not found"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
+// @Failure 500 {object} response.Response "search failed"
+// @Router /api/v1/tasks/workflow_runs/{workflow_run_id} [get]
+func (tc *TaskController) GetWorkflowRun(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	workflowRunID := c.Param("workflow_run_id")
+
+	running := []scheduler.RunningInfo{}
+	for _, run := range tc.scheduler.ListRunning(name) {
+		if run.WorkflowRunID == workflowRunID {
+			running = append(running, run)
+		}
+	}
+
+	logs, err := tc.taskLogDao.FindByWorkflowRunId(name, workflowRunID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Error(response.SearchFailedCode, response.SearchFailedMsg))
+		return
+	}
+
+	if len(running) == 0 && len(logs) == 0 {
+		c.JSON(http.StatusBadRequest, response.Error(response.SearchFailedCode, fmt.Sprintf("%s:%s", response.SearchFailedMsg, "workflow run not found")))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(GetWorkflowRunResponseData{
+		WorkflowRunID: workflowRunID,
+		Running:       running,
+		Logs:          logs,
+	}))
+}
+
+// StreamRun 实时输出
+// @Summary 实时查看任务输出
+// @Description 通过 Server-Sent Events 逐行推送执行中任务的输出，事件名为 stdout/stderr，执行结束时发送 end 事件；已结束的执行直接推送完整输出
+// @Tags 任务管理
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param run_id path string true "运行ID"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} response.Response "search failed: run not found"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
+// @Failure 500 {object} response.Response "search failed"
+// @Router /api/v1/tasks/runs/{run_id}/stream [get]
+func (tc *TaskController) StreamRun(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	runID := c.Param("run_id")
+
+	lines, unsubscribe, ok := tc.scheduler.SubscribeOutput(name, runID)
+	if !ok {
+		// 执行已结束，直接推送日志中的完整输出
+		taskLog, err := tc.findTaskLog(name, runID)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				code = http.StatusBadRequest
+			}
+			c.JSON(code, response.Error(response.SearchFailedCode, response.SearchFailedMsg))
+			return
+		}
+
+		if taskLog.Output != "" {
+			c.SSEvent(scheduler.OutputStreamStdout, taskLog.Output)
+		}
+		if taskLog.ErrOutput != "" {
+			c.SSEvent(scheduler.OutputStreamStderr, taskLog.ErrOutput)
+		}
+		c.SSEvent("end", gin.H{"run_id": runID, "status": taskLog.Status})
+		return
+	}
+	defer unsubscribe()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				c.SSEvent("end", gin.H{"run_id": runID})
+				return false
+			}
+			c.SSEvent(line.Stream, line.Line)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
 
-	tc.scheduler.RunTask(ctx, task)
+// DownloadOutput 下载完整输出
+// @Summary 下载完整输出
+// @Description 下载已结束执行的完整输出，输出超过上限被截断时返回 gzip 压缩文件，否则返回任务日志中的文本；压缩文件超过保留时间被删除后返回 file not found
+// @Tags 任务管理
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param run_id path string true "运行ID"
+// @Param stream query string false "输出类型(stdout/stderr)，默认 stdout"
+// @Success 200 {file} file "output file"
+// @Failure 400 {object} response.Response "invalid request: stream must be stdout or stderr; search failed: run not found; file not found"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token"
+// @Failure 500 {object} response.Response "search failed"
+// @Router /api/v1/tasks/runs/{run_id}/output [get]
+func (tc *TaskController) DownloadOutput(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
 
-	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-		c.JSON(http.StatusInternalServerError, response.Error(response.TaskRunFailedCode, response.TaskRunFailedMsg))
+	runID := c.Param("run_id")
+	stream := c.DefaultQuery("stream", scheduler.OutputStreamStdout)
+	if stream != scheduler.OutputStreamStdout && stream != scheduler.OutputStreamStderr {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, "stream must be stdout or stderr")))
 		return
 	}
 
+	taskLog, err := tc.findTaskLog(name, runID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, response.Error(response.SearchFailedCode, response.SearchFailedMsg))
+		return
+	}
+
+	output, file := taskLog.Output, taskLog.OutputFile
+	if stream == scheduler.OutputStreamStderr {
+		output, file = taskLog.ErrOutput, taskLog.ErrOutputFile
+	}
+
+	if file == "" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.log", runID, stream))
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(output))
+		return
+	}
+
+	path := filepath.Join(tc.outputDir, filepath.Base(file))
+	if _, err := os.Stat(path); err != nil {
+		tc.log.Errorf("output file [%v] of run %v is unavailable: %v", path, runID, err)
+		c.JSON(http.StatusBadRequest, response.Error(response.FileNotFoundCode, response.FileNotFoundMsg))
+		return
+	}
+	c.FileAttachment(path, filepath.Base(file))
+}
+
+// ListRunningResponseData 正在执行的任务列表响应数据
+// @Description 正在执行的任务列表
+type ListRunningResponseData struct {
+	Runs []scheduler.RunningInfo `json:"runs"` // 正在执行的任务
+}
+
+// ListRunning 查询正在执行的任务
+// @Summary 查询正在执行的任务
+// @Description 列出当前用户所有正在执行中的任务，包括运行ID、任务ID、开始时间和进程ID
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=ListRunningResponseData} "success"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Router /api/v1/tasks/running [get]
+func (tc *TaskController) ListRunning(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Success(ListRunningResponseData{Runs: tc.scheduler.ListRunning(name)}))
+}
+
+// CancelRunRequest 取消执行请求
+// @Description 取消正在执行的任务的请求参数
+type CancelRunRequest struct {
+	RunID string `json:"run_id" binding:"required" example:"run_1699123456789"` // 运行ID
+}
+
+// CancelRun 取消正在执行的任务
+// @Summary 取消正在执行的任务
+// @Description 根据运行ID取消当前用户正在执行中的某次运行
+// @Tags 任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CancelRunRequest true "取消执行参数"
+// @Success 200 {object} response.Response "success"
+// @Failure 400 {object} response.Response "invalid request / cancel run failed"
+// @Failure 401 {object} response.Response "Authorization header required / Authorization header format must be Bearer <token> / Invalid or expired token / your request may be unauthorized"
+// @Router /api/v1/tasks/cancel_run [post]
+func (tc *TaskController) CancelRun(c *gin.Context) {
+	name, ok := auth.GetUsernameFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.Error(response.InvalidRequestCode, "your request may be unauthorized"))
+		return
+	}
+
+	var req CancelRunRequest
+	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.InvalidRequestCode, fmt.Sprintf("%s:%s", response.InvalidRequestMsg, err.Error())))
+		return
+	}
+
+	err := tc.scheduler.CancelRun(name, req.RunID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(response.CancelRunFailedCode, fmt.Sprintf("%s:%s", response.CancelRunFailedMsg, err.Error())))
+		return
+	}
 	c.JSON(http.StatusOK, response.Success(nil))
 }
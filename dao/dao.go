@@ -11,7 +11,7 @@ import (
 )
 
 var (
-	ProviderSet = wire.NewSet(NewDB, NewTaskLogDao, NewTaskInfoDao, NewUserDao, NewUserFileDao)
+	ProviderSet = wire.NewSet(NewDB, NewTaskLogDao, NewTaskInfoDao, NewUserDao, NewUserFileDao, NewUserSecretDao)
 )
 
 func NewDB(cf *config.DBConfig, log *zap.SugaredLogger) (*gorm.DB, error) {
@@ -23,10 +23,34 @@ func NewDB(cf *config.DBConfig, log *zap.SugaredLogger) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = db.AutoMigrate(&model2.TaskLog{}, &model2.TaskInfo{}, &model2.User{}, &model2.UserFile{})
+	err = migrate(db)
 	if err != nil {
 		return nil, err
 	}
 	log.Infof("✅ database connected successfully")
 	return db, nil
 }
+
+func migrate(db *gorm.DB) error {
+	// 只有已存在的日志表在本次迁移中新增 status 字段时才需要补齐历史日志，避免每次启动都全表扫描
+	backfillStatus := db.Migrator().HasTable(&model2.TaskLog{}) && !db.Migrator().HasColumn(&model2.TaskLog{}, "status")
+
+	err := db.AutoMigrate(&model2.TaskLog{}, &model2.TaskInfo{}, &model2.User{}, &model2.UserFile{}, &model2.UserSecret{})
+	if err != nil {
+		return err
+	}
+	if !backfillStatus {
+		return nil
+	}
+
+	// 补齐新增 status 字段之前的历史日志：有错误输出的视为失败，其余视为成功
+	err = db.Model(&model2.TaskLog{}).
+		Where("(status = '' OR status IS NULL) AND err_output <> ''").
+		Updates(map[string]interface{}{"status": model2.RunStatusFailed, "exit_code": -1}).Error
+	if err != nil {
+		return err
+	}
+	return db.Model(&model2.TaskLog{}).
+		Where("status = '' OR status IS NULL").
+		Update("status", model2.RunStatusSucceeded).Error
+}
@@ -5,17 +5,34 @@ import (
 	"time"
 )
 
+const (
+	TaskStatusActive    = "active"    // 正常调度中
+	TaskStatusPaused    = "paused"    // 已暂停，不会被调度
+	TaskStatusCompleted = "completed" // 单次执行的任务已触发，不会再被调度
+)
+
 type TaskInfo struct {
-	ID            uint      `gorm:"primarykey"`
-	TaskId        string    `gorm:"column:task_id;type:varchar(255);uniqueIndex"`
-	TaskName      string    `gorm:"column:task_name;index"`
-	ScheduledTime string    `gorm:"column:scheduled_time"`
-	OwnerName     string    `gorm:"column:owner_name"`
-	Description   string    `gorm:"column:description"`
-	JobType       string    `gorm:"column:job_type"`
-	Job           string    `gorm:"column:job;"`
-	CreatedAt     time.Time `gorm:"column:created_at;not null;autoCreateTime"`
-	UpdatedAt     time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+	ID               uint       `gorm:"primarykey"`
+	TaskId           string     `gorm:"column:task_id;type:varchar(255);uniqueIndex"`
+	TaskName         string     `gorm:"column:task_name;index"`
+	ScheduledTime    string     `gorm:"column:scheduled_time"`
+	ScheduleType     string     `gorm:"column:schedule_type;type:varchar(16);not null;default:cron"` // 调度方式(cron/at/every)
+	TimeZone         string     `gorm:"column:time_zone;type:varchar(64)"`                           // IANA 时区名，为空表示服务器本地时区
+	OwnerName        string     `gorm:"column:owner_name"`
+	Description      string     `gorm:"column:description"`
+	JobType          string     `gorm:"column:job_type"`
+	Job              string     `gorm:"column:job;"`
+	Status           string     `gorm:"column:status;type:varchar(32);not null;default:active"`
+	RetryPolicy      string     `gorm:"column:retry_policy;type:text"`                                     // 重试策略(JSON格式)，为空表示不重试
+	Concurrency      string     `gorm:"column:concurrency_policy;type:varchar(32);not null;default:allow"` // 重叠执行的并发策略
+	Upstreams        string     `gorm:"column:upstream_task_ids;type:text"`                                // 上游任务ID列表(JSON数组)，为空表示不依赖其他任务
+	TriggerRule      string     `gorm:"column:trigger_rule;type:varchar(32);not null;default:all_success"` // 上游完成后触发本任务的规则
+	RerunInterrupted bool       `gorm:"column:rerun_interrupted;not null;default:false"`                   // 服务异常退出导致执行中断时，重启后是否重新执行一次
+	MisfirePolicy    string     `gorm:"column:misfire_policy;type:varchar(32);not null;default:skip"`      // 错过触发时间时的处理策略(skip/run_once/run_all)
+	MisfireLimit     int        `gorm:"column:misfire_limit;not null;default:0"`                           // run_all 策略最多补执行的次数，0 表示使用默认值
+	LastFireTime     *time.Time `gorm:"column:last_fire_time;type:datetime"`                               // 上一次被调度触发的时间，从未触发过时为空
+	CreatedAt        time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
 }
 
 func (t *TaskInfo) TableName() string {
@@ -25,6 +42,9 @@ func (t *TaskInfo) TableName() string {
 func (t *TaskInfo) BeforeCreate(tx *gorm.DB) error {
 	t.CreatedAt = time.Now()
 	t.UpdatedAt = time.Now()
+	if t.Status == "" {
+		t.Status = TaskStatusActive
+	}
 	return nil
 }
 
@@ -32,3 +52,13 @@ func (t *TaskInfo) BeforeUpdate(tx *gorm.DB) error {
 	t.UpdatedAt = time.Now()
 	return nil
 }
+
+// IsPaused 任务是否处于暂停状态
+func (t *TaskInfo) IsPaused() bool {
+	return t.Status == TaskStatusPaused
+}
+
+// IsCompleted 单次执行的任务是否已完成
+func (t *TaskInfo) IsCompleted() bool {
+	return t.Status == TaskStatusCompleted
+}
@@ -4,15 +4,74 @@ import (
 	"time"
 )
 
+// 任务执行状态
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+	RunStatusTimedOut  = "timed_out"
+	RunStatusCancelled = "cancelled"
+	RunStatusPanicked  = "panicked"
+	RunStatusSkipped   = "skipped" // 因与上一次执行重叠或工作流触发规则不满足而跳过
+
+	RunStatusLimitExceeded = "limit_exceeded" // 超出资源限制被终止
+	RunStatusInterrupted   = "interrupted"    // 服务停止时未能在期限内结束而被终止
+)
+
+// 任务触发来源
+const (
+	TriggerCron     = "cron"     // 定时触发
+	TriggerManual   = "manual"   // 手动触发
+	TriggerWorkflow = "workflow" // 上游任务完成后由工作流触发
+	TriggerRecovery = "recovery" // 服务重启后重新执行上次被中断的执行
+	TriggerCatchUp  = "catch_up" // 补执行服务停止、任务暂停等期间错过的定时触发
+)
+
 type TaskLog struct {
-	ID        uint      `gorm:"primarykey"`
-	TaskId    string    `gorm:"column:task_id;index"`
-	Name      string    `gorm:"type:varchar(100);column:name;index"`   // 任务名称
-	Content   string    `gorm:"type:text;column:content"`              // 任务内容，比如 shell 命令或者 Go 函数描述
-	Output    string    `gorm:"type:text;column:output"`               // 任务执行输出
-	ErrOutput string    `gorm:"type:text;column:err_output"`           // 任务执行错误输出
-	StartTime time.Time `gorm:"type:datetime;column:start_time;index"` // 任务开始时间
-	EndTime   time.Time `gorm:"type:datetime;column:end_time;index"`   // 任务结束时间
+	ID            uint      `gorm:"primarykey"`
+	TaskId        string    `gorm:"column:task_id;index"`
+	RunId         string    `gorm:"type:varchar(64);column:run_id;index"`          // 单次执行的唯一ID
+	ParentRunId   string    `gorm:"type:varchar(64);column:parent_run_id;index"`   // 重试时指向首次执行的运行ID，恢复执行时指向被中断的运行ID
+	Attempt       int       `gorm:"column:attempt;not null;default:1"`             // 第几次尝试，从1开始
+	Name          string    `gorm:"type:varchar(100);column:name;index"`           // 任务名称
+	Content       string    `gorm:"type:text;column:content"`                      // 任务内容，比如 shell 命令或者 Go 函数描述
+	Output        string    `gorm:"type:text;column:output"`                       // 任务执行输出
+	ErrOutput     string    `gorm:"type:text;column:err_output"`                   // 任务执行错误输出
+	Status        string    `gorm:"type:varchar(32);column:status;index"`          // 执行状态
+	ExitCode      int       `gorm:"column:exit_code;not null;default:0"`           // 进程退出码，未能获取时为 -1
+	Trigger       string    `gorm:"type:varchar(32);column:trigger_type"`          // 触发来源(cron/manual/workflow/recovery/catch_up)
+	WorkflowRunId string    `gorm:"type:varchar(64);column:workflow_run_id;index"` // 所属工作流运行ID，不属于工作流时为空
+	StartTime     time.Time `gorm:"type:datetime;column:start_time;index"`         // 任务开始时间
+	EndTime       time.Time `gorm:"type:datetime;column:end_time;index"`           // 任务结束时间，执行中的记录与开始时间相同
+	HeartbeatAt   time.Time `gorm:"type:datetime;column:heartbeat_at"`             // 执行中定期更新的心跳时间，服务异常退出后据此推断中断时间
+
+	OutputFile    string `gorm:"type:varchar(255);column:output_file"`     // 输出超过上限被截断时，完整输出的压缩文件名(位于输出目录下)
+	ErrOutputFile string `gorm:"type:varchar(255);column:err_output_file"` // 错误输出超过上限被截断时，完整输出的压缩文件名
+
+	// Usage 任务进程的资源使用统计，没有启动进程的任务各项为0
+	Usage ResourceUsage `gorm:"embedded;embeddedPrefix:usage_"`
+}
+
+// ResourceUsage 进程结束时由 rusage 得到的资源使用统计，包含进程等待过的子进程
+type ResourceUsage struct {
+	UserCPUMs              int64 `gorm:"column:user_cpu_ms;not null;default:0" json:"user_cpu_ms"`                           // 用户态 CPU 时间，单位:毫秒
+	SystemCPUMs            int64 `gorm:"column:system_cpu_ms;not null;default:0" json:"system_cpu_ms"`                       // 内核态 CPU 时间，单位:毫秒
+	MaxRSSKB               int64 `gorm:"column:max_rss_kb;not null;default:0" json:"max_rss_kb"`                             // 最大常驻内存，单位:KB
+	InBlocks               int64 `gorm:"column:in_blocks;not null;default:0" json:"in_blocks"`                               // 块设备读次数
+	OutBlocks              int64 `gorm:"column:out_blocks;not null;default:0" json:"out_blocks"`                             // 块设备写次数
+	VoluntaryCtxSwitches   int64 `gorm:"column:voluntary_ctx_switches;not null;default:0" json:"voluntary_ctx_switches"`     // 主动上下文切换次数
+	InvoluntaryCtxSwitches int64 `gorm:"column:involuntary_ctx_switches;not null;default:0" json:"involuntary_ctx_switches"` // 被动上下文切换次数
+}
+
+// Add 累加另一个进程的统计，最大常驻内存取两者中较大的值
+func (u *ResourceUsage) Add(o ResourceUsage) {
+	u.UserCPUMs += o.UserCPUMs
+	u.SystemCPUMs += o.SystemCPUMs
+	u.MaxRSSKB = max(u.MaxRSSKB, o.MaxRSSKB)
+	u.InBlocks += o.InBlocks
+	u.OutBlocks += o.OutBlocks
+	u.VoluntaryCtxSwitches += o.VoluntaryCtxSwitches
+	u.InvoluntaryCtxSwitches += o.InvoluntaryCtxSwitches
 }
 
 func (t *TaskLog) TableName() string {
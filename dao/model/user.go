@@ -3,13 +3,28 @@ package model
 import "time"
 
 type User struct {
-	UserName  string    `gorm:"column:user_name;type:varchar(255);primaryKey"`
-	Password  string    `gorm:"column:password;type:varchar(255);not null"`
-	UseShell  bool      `gorm:"column:use_shell;not null;default:false"`
-	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime"`
-	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+	UserName string `gorm:"column:user_name;type:varchar(255);primaryKey"`
+	Password string `gorm:"column:password;type:varchar(255);not null"`
+	UseShell bool   `gorm:"column:use_shell;not null;default:false"`
+	// Limits 用户任务的默认资源限制，任务可以单独设置更严格的限制，但不能超过这里的值
+	Limits    ResourceLimits `gorm:"embedded;embeddedPrefix:limit_"`
+	CreatedAt time.Time      `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;not null;autoUpdateTime"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
+
+// ResourceLimits 任务进程的资源限制，字段为0表示不限制
+type ResourceLimits struct {
+	CPUSeconds     uint64 `gorm:"column:cpu_seconds;not null;default:0" json:"cpu_seconds,omitempty"`           // CPU 时间，单位:秒
+	AddressSpaceMB uint64 `gorm:"column:address_space_mb;not null;default:0" json:"address_space_mb,omitempty"` // 虚拟地址空间，启用 cgroup 时同时作为内存上限，单位:MB
+	OpenFiles      uint64 `gorm:"column:open_files;not null;default:0" json:"open_files,omitempty"`             // 打开文件数
+	Processes      uint64 `gorm:"column:processes;not null;default:0" json:"processes,omitempty"`               // 进程数
+	FileSizeMB     uint64 `gorm:"column:file_size_mb;not null;default:0" json:"file_size_mb,omitempty"`         // 可写入的单个文件大小，单位:MB
+}
+
+func (l ResourceLimits) IsZero() bool {
+	return l == ResourceLimits{}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// UserSecret 用户密钥，值使用 AES-GCM 加密后保存
+type UserSecret struct {
+	ID        uint      `gorm:"primarykey"`
+	UserName  string    `gorm:"column:user_name;type:varchar(255);uniqueIndex:idx_user_secret_name"`
+	Name      string    `gorm:"column:name;type:varchar(128);uniqueIndex:idx_user_secret_name"`
+	Value     string    `gorm:"column:value;type:text"` // 密文(base64)
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (u *UserSecret) TableName() string {
+	return "user_secrets"
+}
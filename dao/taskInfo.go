@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/chencheng8888/GoDo/dao/model"
 	"gorm.io/gorm"
+	"time"
 )
 
 type TaskInfoDao struct {
@@ -54,3 +55,56 @@ func (t *TaskInfoDao) CountTaskByUserName(userName string) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
+func (t *TaskInfoDao) UpdateTaskStatus(userName, taskId, status string) error {
+	res := t.db.Model(&model.TaskInfo{}).
+		Where("owner_name = ? and task_id = ?", userName, taskId).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("no task has been found, so can not update status")
+	}
+	return nil
+}
+
+// UpdateLastFireTime 记录任务上一次被调度触发的时间，不更新 updated_at
+func (t *TaskInfoDao) UpdateLastFireTime(taskId string, at time.Time) error {
+	return t.db.Model(&model.TaskInfo{}).
+		Where("task_id = ?", taskId).
+		UpdateColumn("last_fire_time", at).Error
+}
+
+// UpdateTaskInfo 更新任务定义，同时写入 taskInfo.Status 以便已完成的单次任务重新生效
+func (t *TaskInfoDao) UpdateTaskInfo(taskInfo *model.TaskInfo) error {
+	res := t.db.Model(&model.TaskInfo{}).
+		Where("owner_name = ? and task_id = ?", taskInfo.OwnerName, taskInfo.TaskId).
+		Updates(map[string]interface{}{
+			"task_name":          taskInfo.TaskName,
+			"scheduled_time":     taskInfo.ScheduledTime,
+			"schedule_type":      taskInfo.ScheduleType,
+			"time_zone":          taskInfo.TimeZone,
+			"description":        taskInfo.Description,
+			"job_type":           taskInfo.JobType,
+			"job":                taskInfo.Job,
+			"status":             taskInfo.Status,
+			"retry_policy":       taskInfo.RetryPolicy,
+			"concurrency_policy": taskInfo.Concurrency,
+			"upstream_task_ids":  taskInfo.Upstreams,
+			"trigger_rule":       taskInfo.TriggerRule,
+			"rerun_interrupted":  taskInfo.RerunInterrupted,
+			"misfire_policy":     taskInfo.MisfirePolicy,
+			"misfire_limit":      taskInfo.MisfireLimit,
+			"updated_at":         time.Now(),
+		})
+
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("no task has been found, so can not be updated")
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package dao
 
 import (
+	"errors"
+	"time"
+
 	"github.com/chencheng8888/GoDo/dao/model"
 	"gorm.io/gorm"
 )
@@ -17,6 +20,69 @@ func (t *TaskLogDao) CreateTaskLog(taskLog *model.TaskLog) error {
 	return t.db.Create(taskLog).Error
 }
 
+// SaveTaskLogs 在一个事务中批量保存任务日志
+// 执行开始时写入的 running 记录只在该运行ID不存在时插入，执行结束时的记录覆盖同一运行ID的已有记录
+// 历史日志没有运行ID，run_id 无法建唯一索引，调用方需保证不会并发保存，由 scheduler.TaskLogWriter 的后台协程串行调用
+func (t *TaskLogDao) SaveTaskLogs(taskLogs []model.TaskLog) error {
+	if len(taskLogs) == 0 {
+		return nil
+	}
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		for _, taskLog := range taskLogs {
+			if taskLog.RunId != "" {
+				var existing model.TaskLog
+				err := tx.Select("id").Where("run_id = ?", taskLog.RunId).Take(&existing).Error
+				if err == nil {
+					if taskLog.Status == model.RunStatusRunning {
+						continue
+					}
+					taskLog.ID = existing.ID
+					if err := tx.Save(&taskLog).Error; err != nil {
+						return err
+					}
+					continue
+				}
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+			}
+			if err := tx.Create(&taskLog).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TouchTaskLogs 更新仍处于 running 状态的任务日志的心跳时间
+func (t *TaskLogDao) TouchTaskLogs(runIds []string, at time.Time) error {
+	if len(runIds) == 0 {
+		return nil
+	}
+	return t.db.Model(&model.TaskLog{}).
+		Where("run_id IN ? AND status = ?", runIds, model.RunStatusRunning).
+		Update("heartbeat_at", at).Error
+}
+
+// FindRunningTaskLogs 查询所有处于 running 状态的任务日志
+func (t *TaskLogDao) FindRunningTaskLogs() ([]model.TaskLog, error) {
+	var logs []model.TaskLog
+	err := t.db.Where("status = ?", model.RunStatusRunning).Order("start_time ASC").Find(&logs).Error
+	return logs, err
+}
+
+// InterruptTaskLog 将仍处于 running 状态的任务日志标记为 interrupted
+func (t *TaskLogDao) InterruptTaskLog(id uint, endTime time.Time, errOutput string) error {
+	return t.db.Model(&model.TaskLog{}).
+		Where("id = ? AND status = ?", id, model.RunStatusRunning).
+		Updates(map[string]any{
+			"status":     model.RunStatusInterrupted,
+			"exit_code":  -1,
+			"end_time":   endTime,
+			"err_output": errOutput,
+		}).Error
+}
+
 func (t *TaskLogDao) FindByUserName(ownerName string, page, pageSize int) ([]model.TaskLog, int64, error) {
 	var (
 		logs  []model.TaskLog
@@ -48,3 +114,26 @@ func (t *TaskLogDao) FindByUserName(ownerName string, page, pageSize int) ([]mod
 
 	return logs, total, nil
 }
+
+// FindByRunId 按运行ID查询用户的任务日志
+func (t *TaskLogDao) FindByRunId(ownerName, runId string) (*model.TaskLog, error) {
+	var taskLog model.TaskLog
+	err := t.db.Table("task_logs tl").
+		Select("tl.*").
+		Joins("JOIN task_infos ti ON tl.task_id = ti.task_id").
+		Where("ti.owner_name = ? AND tl.run_id = ?", ownerName, runId).
+		Take(&taskLog).Error
+	return &taskLog, err
+}
+
+// FindByWorkflowRunId 按工作流运行ID查询用户该次工作流中各节点的任务日志
+func (t *TaskLogDao) FindByWorkflowRunId(ownerName, workflowRunId string) ([]model.TaskLog, error) {
+	var logs []model.TaskLog
+	err := t.db.Table("task_logs tl").
+		Select("tl.*").
+		Joins("JOIN task_infos ti ON tl.task_id = ti.task_id").
+		Where("ti.owner_name = ? AND tl.workflow_run_id = ?", ownerName, workflowRunId).
+		Order("tl.start_time ASC").
+		Find(&logs).Error
+	return logs, err
+}
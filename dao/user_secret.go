@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	UserSecretNotFoundErr = errors.New("user secret not found")
+)
+
+type UserSecretDao struct {
+	db *gorm.DB
+}
+
+func NewUserSecretDao(db *gorm.DB) *UserSecretDao {
+	return &UserSecretDao{db: db}
+}
+
+// SaveSecret 保存用户密钥，同名密钥已存在时覆盖
+func (u *UserSecretDao) SaveSecret(secret *model.UserSecret) error {
+	return u.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_name"}, {Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": secret.Value, "updated_at": time.Now()}),
+	}).Create(secret).Error
+}
+
+// ListSecrets 列出用户的密钥，不查询密文
+func (u *UserSecretDao) ListSecrets(userName string) ([]model.UserSecret, error) {
+	var secrets []model.UserSecret
+	err := u.db.Model(&model.UserSecret{}).
+		Select("id", "user_name", "name", "created_at", "updated_at").
+		Where("user_name = ?", userName).
+		Order("name").
+		Find(&secrets).Error
+	return secrets, err
+}
+
+// GetSecrets 按名称查询用户的密钥
+func (u *UserSecretDao) GetSecrets(userName string, names []string) ([]model.UserSecret, error) {
+	var secrets []model.UserSecret
+	err := u.db.Model(&model.UserSecret{}).
+		Where("user_name = ? AND name IN ?", userName, names).
+		Find(&secrets).Error
+	return secrets, err
+}
+
+func (u *UserSecretDao) DeleteSecret(userName, name string) error {
+	res := u.db.Where("user_name = ? AND name = ?", userName, name).Delete(&model.UserSecret{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return UserSecretNotFoundErr
+	}
+	return nil
+}
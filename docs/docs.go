@@ -26,11 +26,6 @@ const docTemplate = `{
     "paths": {
         "/api/v1/auth/login": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "登录",
                 "consumes": [
                     "application/json"
@@ -90,17 +85,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/tasks/add_shell_task": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "创建一个新的Shell任务，支持定时执行，任务所有者从JWT token中获取",
+                ]
+            }
+        },
+        "/api/v1/schedules/preview": {
+            "post": {
+                "description": "按调度器当前的解析配置(是否启用秒级)解析调度描述，返回接下来的触发时间和中英文解释",
                 "consumes": [
                     "application/json"
                 ],
@@ -108,17 +103,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "任务管理"
+                    "调度"
                 ],
-                "summary": "添加Shell任务",
+                "summary": "调度预览",
                 "parameters": [
                     {
-                        "description": "任务创建参数",
+                        "description": "调度预览参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.AddShellTaskRequest"
+                            "$ref": "#/definitions/controller.PreviewScheduleRequest"
                         }
                     }
                 ],
@@ -134,7 +129,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller.AddShellTaskResponseData"
+                                            "$ref": "#/definitions/controller.PreviewScheduleResponseData"
                                         }
                                     }
                                 }
@@ -142,13 +137,56 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request: invalid request",
+                        "description": "invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer \u003ctoken\u003e; Invalid or expired token",
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/secrets": {
+            "get": {
+                "description": "列出当前用户保存的密钥名称，不返回密钥值",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "密钥管理"
+                ],
+                "summary": "列出密钥",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.ListSecretsResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -158,18 +196,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "503": {
+                        "description": "secret store not configured",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
-                }
-            }
-        },
-        "/api/v1/tasks/delete": {
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "根据任务ID和JWT token中的用户名删除指定任务",
+                ]
+            },
+            "put": {
+                "description": "加密保存当前用户的密钥，同名密钥已存在时覆盖",
                 "consumes": [
                     "application/json"
                 ],
@@ -177,23 +219,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "任务管理"
+                    "密钥管理"
                 ],
-                "summary": "删除任务",
+                "summary": "保存密钥",
                 "parameters": [
                     {
-                        "description": "删除任务参数",
+                        "description": "密钥参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.DeleteTaskRequest"
+                            "$ref": "#/definitions/controller.SaveSecretRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "success",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -211,22 +253,26 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "删除任务失败",
+                        "description": "save secret failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "secret store not configured",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/tasks/delete_file": {
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "删除对应的文件",
+                ]
+            },
+            "delete": {
+                "description": "删除当前用户的密钥，引用该密钥的任务在下次执行时会失败",
                 "consumes": [
                     "application/json"
                 ],
@@ -234,17 +280,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "任务管理"
+                    "密钥管理"
                 ],
-                "summary": "删除对应的文件",
+                "summary": "删除密钥",
                 "parameters": [
                     {
-                        "description": "文件删除参数",
+                        "description": "删除密钥参数",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.DeleteFileRequest"
+                            "$ref": "#/definitions/controller.DeleteSecretRequest"
                         }
                     }
                 ],
@@ -252,96 +298,44 @@ const docTemplate = `{
                     "200": {
                         "description": "success",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.Response"
                         }
                     },
                     "400": {
-                        "description": "Bad Request: invalid request; file not found",
+                        "description": "invalid request / user secret not found",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header format must be Bearer \u003ctoken\u003e; Invalid or expired token; your user account may have been deleted",
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error: delete file failed",
+                        "description": "delete secret failed",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/tasks/list": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "根据 JWT token 中的用户名获取该用户的所有任务",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "任务管理"
-                ],
-                "summary": "获取用户任务列表",
-                "responses": {
-                    "200": {
-                        "description": "获取成功",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/controller.ListTaskResponseData"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized:",
+                    "503": {
+                        "description": "secret store not configured",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/tasks/list_files": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "查询已有文件",
+                ]
+            }
+        },
+        "/api/v1/tasks": {
+            "post": {
+                "description": "按 job_type 将 job 字段交给对应的任务类型解析和校验后创建任务，支持所有已注册的任务类型",
                 "consumes": [
                     "application/json"
                 ],
@@ -351,7 +345,18 @@ const docTemplate = `{
                 "tags": [
                     "任务管理"
                 ],
-                "summary": "查询已有文件",
+                "summary": "添加任务",
+                "parameters": [
+                    {
+                        "description": "任务创建参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.AddTaskRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "success",
@@ -364,13 +369,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller.ListFilesResponseData"
+                                            "$ref": "#/definitions/controller.AddShellTaskResponseData"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad request: invalid request; job type unknown",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer \u003ctoken\u003e; Invalid or expired token",
                         "schema": {
@@ -378,22 +389,22 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error: search failed",
+                        "description": "search failed",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/tasks/logs": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "按用户名分页查询任务日志，如果未传 user_name 则默认当前登录用户",
+                ]
+            }
+        },
+        "/api/v1/tasks/add_shell_task": {
+            "post": {
+                "description": "创建一个新的Shell任务，支持定时执行，任务所有者从JWT token中获取。已废弃，请使用 POST /api/v1/tasks",
                 "consumes": [
                     "application/json"
                 ],
@@ -403,21 +414,17 @@ const docTemplate = `{
                 "tags": [
                     "任务管理"
                 ],
-                "summary": "查询任务日志",
+                "summary": "添加Shell任务",
+                "deprecated": true,
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页条数",
-                        "name": "page_size",
-                        "in": "query",
-                        "required": true
+                        "description": "任务创建参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.AddShellTaskRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -432,7 +439,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller.ListTaskLogResponseData"
+                                            "$ref": "#/definitions/controller.AddShellTaskResponseData"
                                         }
                                     }
                                 }
@@ -440,13 +447,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad request: invalid request",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "401": {
-                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer \u003ctoken\u003e; Invalid or expired token",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -457,17 +464,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
-            }
-        },
-        "/api/v1/tasks/run": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "运行任务",
+                ]
+            }
+        },
+        "/api/v1/tasks/cancel_run": {
+            "post": {
+                "description": "根据运行ID取消当前用户正在执行中的某次运行",
                 "consumes": [
                     "application/json"
                 ],
@@ -477,28 +484,138 @@ const docTemplate = `{
                 "tags": [
                     "任务管理"
                 ],
-                "summary": "运行任务",
+                "summary": "取消正在执行的任务",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "任务id",
-                        "name": "task_id",
-                        "in": "query",
-                        "required": true
+                        "description": "取消执行参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.CancelRunRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "success",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request / cancel run failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/delete": {
+            "delete": {
+                "description": "根据任务ID和JWT token中的用户名删除指定任务",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "删除任务",
+                "parameters": [
+                    {
+                        "description": "删除任务参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.DeleteTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "删除任务失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/delete_file": {
+            "delete": {
+                "description": "删除对应的文件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "删除对应的文件",
+                "parameters": [
+                    {
+                        "description": "文件删除参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.DeleteFileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
                                             "type": "object"
                                         }
                                     }
@@ -507,36 +624,79 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad Request: invalid request; file not found",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "401": {
-                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header format must be Bearer \u003ctoken\u003e; Invalid or expired token; your user account may have been deleted",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "500": {
-                        "description": "search failed / task run failed",
+                        "description": "Internal Server Error: delete file failed",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/api/v1/tasks/upload_file": {
-            "post": {
+        "/api/v1/tasks/job_types": {
+            "get": {
+                "description": "列出所有已注册的任务类型及创建任务时 job 字段的 JSON Schema",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "查询任务类型",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.ListJobTypesResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "上传文件到服务器，用于后续任务执行",
+                ]
+            }
+        },
+        "/api/v1/tasks/list": {
+            "get": {
+                "description": "根据 JWT token 中的用户名获取该用户的所有任务",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -544,19 +704,10 @@ const docTemplate = `{
                 "tags": [
                     "任务管理"
                 ],
-                "summary": "上传文件",
-                "parameters": [
-                    {
-                        "type": "file",
-                        "description": "文件",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    }
-                ],
+                "summary": "获取用户任务列表",
                 "responses": {
                     "200": {
-                        "description": "上传成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -566,45 +717,1727 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller.UploadScriptResponseData"
+                                            "$ref": "#/definitions/controller.ListTaskResponseData"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request: file not uploaded; file too large; file number limit exceeded",
+                    "401": {
+                        "description": "Unauthorized:",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/list_files": {
+            "get": {
+                "description": "查询已有文件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "查询已有文件",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.ListFilesResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
                     "401": {
-                        "description": "Unauthorized: Authorization header required; wrong format (must be Bearer \u003ctoken\u003e); invalid or expired token; your request may be unauthorized",
+                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer \u003ctoken\u003e; Invalid or expired token",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "500": {
-                        "description": "Server Error: file save failed; search failed",
+                        "description": "Internal Server Error: search failed",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
-        }
-    },
-    "definitions": {
-        "controller.AddShellTaskRequest": {
-            "description": "添加Shell任务的请求参数",
+        },
+        "/api/v1/tasks/logs": {
+            "get": {
+                "description": "按用户名分页查询任务日志，如果未传 user_name 则默认当前登录用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "查询任务日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页条数",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.ListTaskLogResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "search failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/pause": {
+            "post": {
+                "description": "暂停指定任务的调度，任务定义和日志保留，可通过恢复接口重新调度",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "暂停任务",
+                "parameters": [
+                    {
+                        "description": "暂停任务参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.PauseTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request / pause task failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/resume": {
+            "post": {
+                "description": "恢复已暂停任务的调度",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "恢复任务",
+                "parameters": [
+                    {
+                        "description": "恢复任务参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResumeTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request / resume task failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/run": {
+            "post": {
+                "description": "手动触发任务，任务提交到协程池异步执行，立即返回运行ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "运行任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务id",
+                        "name": "task_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.RunTaskResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "search failed / task run failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/running": {
+            "get": {
+                "description": "列出当前用户所有正在执行中的任务，包括运行ID、任务ID、开始时间和进程ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "查询正在执行的任务",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.ListRunningResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token / your request may be unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/runs/{run_id}": {
+            "get": {
+                "description": "根据运行ID查询执行状态、输出和耗时，可用于轮询手动触发的任务",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "查询单次执行",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "运行ID",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.GetRunResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "search failed: run not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "search failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/runs/{run_id}/output": {
+            "get": {
+                "description": "下载已结束执行的完整输出，输出超过上限被截断时返回 gzip 压缩文件，否则返回任务日志中的文本；压缩文件超过保留时间被删除后返回 file not found",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "下载完整输出",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "运行ID",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "输出类型(stdout/stderr)，默认 stdout",
+                        "name": "stream",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "output file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request: stream must be stdout or stderr; search failed: run not found; file not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "search failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/runs/{run_id}/stream": {
+            "get": {
+                "description": "通过 Server-Sent Events 逐行推送执行中任务的输出，事件名为 stdout/stderr，执行结束时发送 end 事件；已结束的执行直接推送完整输出",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "实时查看任务输出",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "运行ID",
+                        "name": "run_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "search failed: run not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "search failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/update_shell_task": {
+            "put": {
+                "description": "原地修改任务的名称、描述、Cron表达式和Shell参数，任务ID和已有日志保持不变。已废弃，请使用 PUT /api/v1/tasks/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "更新Shell任务",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "任务更新参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UpdateShellTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request: invalid request; update task failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer \u003ctoken\u003e; Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/upload_file": {
+            "post": {
+                "description": "上传文件到服务器，用于后续任务执行",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "上传文件",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上传成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.UploadScriptResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request: file not uploaded; file too large; file number limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Authorization header required; wrong format (must be Bearer \u003ctoken\u003e); invalid or expired token; your request may be unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error: file save failed; search failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/workflow_runs/{workflow_run_id}": {
+            "get": {
+                "description": "根据工作流运行ID查询本次运行中各节点的状态和任务日志，被触发规则跳过的节点状态为 skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "查询工作流运行",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作流运行ID",
+                        "name": "workflow_run_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller.GetWorkflowRunResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "search failed: workflow run not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization header required / Authorization header format must be Bearer \u003ctoken\u003e / Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "search failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v1/tasks/{id}": {
+            "put": {
+                "description": "按 job_type 解析 job 字段后原地替换任务的调度和内容，任务ID和已有日志保持不变，支持所有已注册的任务类型",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "任务管理"
+                ],
+                "summary": "更新任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "任务更新参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UpdateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request: invalid request; job type unknown; update task failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: your request may be unauthorized; Authorization header required; Authorization header must be Bearer \u003ctoken\u003e; Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "返回调度器状态和等待或正在执行的任务数；服务停止时进入 draining 状态并返回 503，负载均衡应不再转发请求",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "调度"
+                ],
+                "summary": "健康检查",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/scheduler.HealthInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "draining",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/scheduler.HealthInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.AddShellTaskRequest": {
+            "description": "添加Shell任务的请求参数",
+            "type": "object",
+            "required": [
+                "description",
+                "task_name",
+                "upstream_task_ids"
+            ],
+            "properties": {
+                "args": {
+                    "description": "命令参数",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "--full"
+                    ]
+                },
+                "command": {
+                    "description": "执行命令",
+                    "type": "string",
+                    "example": "./backup.sh"
+                },
+                "concurrency_policy": {
+                    "description": "重叠执行策略(allow/forbid/replace/queue)，默认allow",
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "forbid",
+                        "replace",
+                        "queue"
+                    ],
+                    "example": "forbid"
+                },
+                "description": {
+                    "description": "任务描述",
+                    "type": "string",
+                    "example": "每日数据备份任务"
+                },
+                "env": {
+                    "description": "环境变量，最多50个，值中可通过 ${secret:NAME} 引用密钥",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "DB_PASSWORD": "${secret:db_password}"
+                    }
+                },
+                "limits": {
+                    "description": "资源限制，不传表示使用用户的默认限制",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResourceLimitsRequest"
+                        }
+                    ]
+                },
+                "misfire_limit": {
+                    "description": "run_all 策略最多补执行的次数，默认10",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "misfire_policy": {
+                    "description": "服务停止或任务暂停期间错过触发时间时的处理策略(skip/run_once/run_all)，默认skip",
+                    "type": "string",
+                    "enum": [
+                        "skip",
+                        "run_once",
+                        "run_all"
+                    ],
+                    "example": "run_once"
+                },
+                "rerun_interrupted": {
+                    "description": "服务异常退出导致执行中断时，重启后是否重新执行一次，默认false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "retry": {
+                    "description": "重试策略，不传表示不重试",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.RetryPolicyRequest"
+                        }
+                    ]
+                },
+                "schedule_type": {
+                    "description": "调度方式(cron/at/every)，默认cron",
+                    "type": "string",
+                    "enum": [
+                        "cron",
+                        "at",
+                        "every"
+                    ],
+                    "example": "cron"
+                },
+                "scheduled_time": {
+                    "description": "调度描述：cron表达式(支持秒级)、at的执行时间(如2026-11-01 03:00:00)或every的间隔(如90s、1h+15m)，设置上游任务时忽略",
+                    "type": "string",
+                    "example": "0 2 * * * *"
+                },
+                "task_name": {
+                    "description": "任务名称",
+                    "type": "string",
+                    "example": "daily-backup"
+                },
+                "time_zone": {
+                    "description": "IANA时区名，默认服务器本地时区",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "timeout": {
+                    "description": "超时时间(秒)，最大2小时",
+                    "type": "integer",
+                    "example": 1800
+                },
+                "trigger_rule": {
+                    "description": "触发规则(all_success/any_failed/all_done)，默认all_success",
+                    "type": "string",
+                    "enum": [
+                        "all_success",
+                        "any_failed",
+                        "all_done"
+                    ],
+                    "example": "all_success"
+                },
+                "upstream_task_ids": {
+                    "description": "上游任务ID，设置后任务不再定时触发，而是在上游结束后由工作流触发",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task_1699123456789"
+                    ]
+                },
+                "use_shell": {
+                    "description": "是否使用Shell",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "controller.AddShellTaskResponseData": {
+            "description": "添加任务成功响应数据",
+            "type": "object",
+            "properties": {
+                "task_id": {
+                    "description": "新创建的任务ID",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "controller.AddTaskRequest": {
+            "description": "通用的任务创建参数，job 字段的结构由 job_type 决定，可通过 /api/v1/tasks/job_types 查询",
+            "type": "object",
+            "required": [
+                "description",
+                "job",
+                "job_type",
+                "task_name",
+                "upstream_task_ids"
+            ],
+            "properties": {
+                "concurrency_policy": {
+                    "description": "重叠执行策略(allow/forbid/replace/queue)，默认allow",
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "forbid",
+                        "replace",
+                        "queue"
+                    ],
+                    "example": "forbid"
+                },
+                "description": {
+                    "description": "任务描述",
+                    "type": "string",
+                    "example": "每日数据备份任务"
+                },
+                "job": {
+                    "description": "任务详情，结构见对应任务类型的 JSON Schema",
+                    "type": "object"
+                },
+                "job_type": {
+                    "description": "任务类型",
+                    "type": "string",
+                    "example": "shell"
+                },
+                "misfire_limit": {
+                    "description": "run_all 策略最多补执行的次数，默认10",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "misfire_policy": {
+                    "description": "服务停止或任务暂停期间错过触发时间时的处理策略(skip/run_once/run_all)，默认skip",
+                    "type": "string",
+                    "enum": [
+                        "skip",
+                        "run_once",
+                        "run_all"
+                    ],
+                    "example": "run_once"
+                },
+                "rerun_interrupted": {
+                    "description": "服务异常退出导致执行中断时，重启后是否重新执行一次，默认false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "retry": {
+                    "description": "重试策略，不传表示不重试",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.RetryPolicyRequest"
+                        }
+                    ]
+                },
+                "schedule_type": {
+                    "description": "调度方式(cron/at/every)，默认cron",
+                    "type": "string",
+                    "enum": [
+                        "cron",
+                        "at",
+                        "every"
+                    ],
+                    "example": "cron"
+                },
+                "scheduled_time": {
+                    "description": "调度描述：cron表达式(支持秒级)、at的执行时间(如2026-11-01 03:00:00)或every的间隔(如90s、1h+15m)，设置上游任务时忽略",
+                    "type": "string",
+                    "example": "0 2 * * * *"
+                },
+                "task_name": {
+                    "description": "任务名称",
+                    "type": "string",
+                    "example": "daily-backup"
+                },
+                "time_zone": {
+                    "description": "IANA时区名，默认服务器本地时区",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "trigger_rule": {
+                    "description": "触发规则(all_success/any_failed/all_done)，默认all_success",
+                    "type": "string",
+                    "enum": [
+                        "all_success",
+                        "any_failed",
+                        "all_done"
+                    ],
+                    "example": "all_success"
+                },
+                "upstream_task_ids": {
+                    "description": "上游任务ID，设置后任务不再定时触发，而是在上游结束后由工作流触发",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task_1699123456789"
+                    ]
+                }
+            }
+        },
+        "controller.CancelRunRequest": {
+            "description": "取消正在执行的任务的请求参数",
+            "type": "object",
+            "required": [
+                "run_id"
+            ],
+            "properties": {
+                "run_id": {
+                    "description": "运行ID",
+                    "type": "string",
+                    "example": "run_1699123456789"
+                }
+            }
+        },
+        "controller.DeleteFileRequest": {
+            "type": "object",
+            "properties": {
+                "file_name": {
+                    "description": "文件名",
+                    "type": "string",
+                    "example": "1699123456789-script.sh"
+                }
+            }
+        },
+        "controller.DeleteSecretRequest": {
+            "description": "删除密钥的请求参数",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "密钥名称",
+                    "type": "string",
+                    "example": "db_password"
+                }
+            }
+        },
+        "controller.DeleteTaskRequest": {
+            "description": "删除任务的请求参数",
+            "type": "object",
+            "required": [
+                "task_id"
+            ],
+            "properties": {
+                "task_id": {
+                    "description": "任务ID",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "controller.GetRunResponseData": {
+            "description": "单次执行的状态、输出和耗时",
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "description": "第几次尝试",
+                    "type": "integer",
+                    "example": 1
+                },
+                "duration": {
+                    "description": "已执行时长",
+                    "type": "string",
+                    "example": "1.5s"
+                },
+                "end_time": {
+                    "description": "结束时间，执行结束后才有",
+                    "type": "string"
+                },
+                "err_output": {
+                    "description": "错误输出，执行中时为最近的输出",
+                    "type": "string",
+                    "example": ""
+                },
+                "err_output_file": {
+                    "description": "错误输出被截断时完整输出的文件名",
+                    "type": "string"
+                },
+                "exit_code": {
+                    "description": "进程退出码，执行结束后才有",
+                    "type": "integer",
+                    "example": 0
+                },
+                "output": {
+                    "description": "标准输出，执行中时为最近的输出",
+                    "type": "string",
+                    "example": "Hello World"
+                },
+                "output_file": {
+                    "description": "输出被截断时完整输出的文件名，可通过 /runs/{run_id}/output 下载",
+                    "type": "string"
+                },
+                "parent_run_id": {
+                    "description": "重试时指向首次执行的运行ID",
+                    "type": "string",
+                    "example": ""
+                },
+                "run_id": {
+                    "description": "运行ID",
+                    "type": "string",
+                    "example": "run_1699123456789"
+                },
+                "start_time": {
+                    "description": "开始时间",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "执行状态(pending/running/succeeded/failed/timed_out/cancelled/panicked/skipped)",
+                    "type": "string",
+                    "example": "succeeded"
+                },
+                "task_id": {
+                    "description": "任务ID",
+                    "type": "string",
+                    "example": "task_1699123456789"
+                },
+                "task_name": {
+                    "description": "任务名称",
+                    "type": "string",
+                    "example": "daily-backup"
+                },
+                "trigger": {
+                    "description": "触发来源",
+                    "type": "string",
+                    "example": "manual"
+                },
+                "usage": {
+                    "description": "资源使用统计，执行结束后才有",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourceUsage"
+                        }
+                    ]
+                },
+                "workflow_run_id": {
+                    "description": "所属工作流运行ID",
+                    "type": "string",
+                    "example": "wf_1699123456789"
+                }
+            }
+        },
+        "controller.GetWorkflowRunResponseData": {
+            "description": "一次工作流运行中各节点的执行情况",
+            "type": "object",
+            "properties": {
+                "logs": {
+                    "description": "已结束节点的任务日志，按开始时间排序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TaskLog"
+                    }
+                },
+                "running": {
+                    "description": "等待中或执行中的节点",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.RunningInfo"
+                    }
+                },
+                "workflow_run_id": {
+                    "description": "工作流运行ID",
+                    "type": "string",
+                    "example": "wf_1699123456789"
+                }
+            }
+        },
+        "controller.JobTypeResponse": {
+            "description": "已注册的任务类型及其 job 字段的 JSON Schema",
+            "type": "object",
+            "properties": {
+                "job_type": {
+                    "description": "任务类型",
+                    "type": "string",
+                    "example": "shell"
+                },
+                "schema": {
+                    "description": "job 字段的 JSON Schema",
+                    "type": "object"
+                }
+            }
+        },
+        "controller.ListFilesResponseData": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controller.ListJobTypesResponseData": {
+            "type": "object",
+            "properties": {
+                "job_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.JobTypeResponse"
+                    }
+                }
+            }
+        },
+        "controller.ListRunningResponseData": {
+            "description": "正在执行的任务列表",
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "description": "正在执行的任务",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.RunningInfo"
+                    }
+                }
+            }
+        },
+        "controller.ListSecretsResponseData": {
+            "description": "当前用户的密钥列表",
+            "type": "object",
+            "properties": {
+                "secrets": {
+                    "description": "密钥列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.SecretResponse"
+                    }
+                }
+            }
+        },
+        "controller.ListTaskLogResponseData": {
+            "type": "object",
+            "properties": {
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TaskLog"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "controller.ListTaskResponseData": {
+            "description": "任务列表响应数据结构",
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "description": "任务列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.TaskResponse"
+                    }
+                }
+            }
+        },
+        "controller.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.LoginResponseData": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.PauseTaskRequest": {
+            "description": "暂停任务的请求参数",
+            "type": "object",
+            "required": [
+                "task_id"
+            ],
+            "properties": {
+                "task_id": {
+                    "description": "任务ID",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "controller.PreviewScheduleRequest": {
+            "description": "调度预览的请求参数",
+            "type": "object",
+            "required": [
+                "scheduled_time"
+            ],
+            "properties": {
+                "count": {
+                    "description": "返回的触发次数，默认5",
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "schedule_type": {
+                    "description": "调度方式(cron/at/every)，默认cron",
+                    "type": "string",
+                    "enum": [
+                        "cron",
+                        "at",
+                        "every"
+                    ],
+                    "example": "cron"
+                },
+                "scheduled_time": {
+                    "description": "调度描述，格式与创建任务时相同",
+                    "type": "string",
+                    "example": "0 0 2 * * *"
+                },
+                "time_zone": {
+                    "description": "IANA时区名，默认服务器本地时区",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                }
+            }
+        },
+        "controller.PreviewScheduleResponseData": {
+            "description": "接下来的触发时间和调度解释",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "中英文解释",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/scheduler.ScheduleDescription"
+                        }
+                    ]
+                },
+                "next_run_times": {
+                    "description": "接下来的触发时间",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controller.ResourceLimitsRequest": {
+            "description": "任务进程的资源限制，仅在 Linux 上生效，不能超过用户的默认限制，字段为0表示使用用户默认值",
+            "type": "object",
+            "properties": {
+                "address_space_mb": {
+                    "description": "虚拟地址空间(MB)，启用 cgroup 时同时作为内存上限",
+                    "type": "integer",
+                    "example": 2048
+                },
+                "cpu_seconds": {
+                    "description": "CPU 时间(秒)",
+                    "type": "integer",
+                    "example": 600
+                },
+                "file_size_mb": {
+                    "description": "可写入的单个文件大小(MB)",
+                    "type": "integer",
+                    "example": 512
+                },
+                "open_files": {
+                    "description": "打开文件数",
+                    "type": "integer",
+                    "example": 1024
+                },
+                "processes": {
+                    "description": "进程数",
+                    "type": "integer",
+                    "example": 64
+                }
+            }
+        },
+        "controller.ResumeTaskRequest": {
+            "description": "恢复任务的请求参数",
+            "type": "object",
+            "required": [
+                "task_id"
+            ],
+            "properties": {
+                "task_id": {
+                    "description": "任务ID",
+                    "type": "string",
+                    "example": "12345"
+                }
+            }
+        },
+        "controller.RetryPolicyRequest": {
+            "description": "任务失败后的重试策略",
+            "type": "object",
+            "required": [
+                "max_attempts"
+            ],
+            "properties": {
+                "backoff": {
+                    "description": "退避方式",
+                    "type": "string",
+                    "enum": [
+                        "fixed",
+                        "exponential"
+                    ],
+                    "example": "exponential"
+                },
+                "interval": {
+                    "description": "首次重试前的等待时间(秒)",
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 0,
+                    "example": 10
+                },
+                "max_attempts": {
+                    "description": "最大尝试次数(含首次执行)",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "max_interval": {
+                    "description": "指数退避的最大等待时间(秒)",
+                    "type": "integer",
+                    "maximum": 86400,
+                    "minimum": 0,
+                    "example": 300
+                },
+                "retry_on_exit_codes": {
+                    "description": "仅在这些退出码时重试",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1
+                    ]
+                },
+                "retry_on_timeout": {
+                    "description": "超时时重试",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "controller.RunTaskResponseData": {
+            "description": "手动触发任务后返回的运行ID",
+            "type": "object",
+            "properties": {
+                "run_id": {
+                    "description": "运行ID，可用于查询执行状态",
+                    "type": "string",
+                    "example": "run_1699123456789"
+                }
+            }
+        },
+        "controller.SaveSecretRequest": {
+            "description": "保存密钥的请求参数，同名密钥已存在时覆盖",
+            "type": "object",
+            "required": [
+                "name",
+                "value"
+            ],
+            "properties": {
+                "name": {
+                    "description": "密钥名称，只能包含字母、数字、下划线、点和中划线，任务中通过 ${secret:NAME} 引用",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "db_password"
+                },
+                "value": {
+                    "description": "密钥值，加密后保存，不会再通过接口返回",
+                    "type": "string",
+                    "maxLength": 8192,
+                    "example": "p@ssw0rd"
+                }
+            }
+        },
+        "controller.SecretResponse": {
+            "description": "密钥信息",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "密钥名称",
+                    "type": "string",
+                    "example": "db_password"
+                },
+                "updated_at": {
+                    "description": "最后修改时间",
+                    "type": "string"
+                }
+            }
+        },
+        "controller.TaskResponse": {
+            "description": "任务信息响应结构",
+            "type": "object",
+            "properties": {
+                "concurrency_policy": {
+                    "description": "重叠执行策略",
+                    "type": "string",
+                    "example": "forbid"
+                },
+                "description": {
+                    "description": "任务描述",
+                    "type": "string",
+                    "example": "每日数据备份任务"
+                },
+                "id": {
+                    "description": "任务ID",
+                    "type": "string",
+                    "example": "12345"
+                },
+                "job": {
+                    "description": "任务详情(JSON格式)",
+                    "type": "string",
+                    "example": "{\"command\":\"/bin/bash\",\"args\":[\"backup.sh\"]}"
+                },
+                "job_type": {
+                    "description": "任务类型",
+                    "type": "string",
+                    "example": "shell"
+                },
+                "misfire_limit": {
+                    "description": "run_all 策略最多补执行的次数，0 表示默认值",
+                    "type": "integer",
+                    "example": 0
+                },
+                "misfire_policy": {
+                    "description": "错过触发时间时的处理策略",
+                    "type": "string",
+                    "example": "run_once"
+                },
+                "next_run_time": {
+                    "description": "下一次触发时间(任务时区)，暂停或已完成的任务没有",
+                    "type": "string"
+                },
+                "owner_name": {
+                    "description": "任务拥有者",
+                    "type": "string",
+                    "example": "admin"
+                },
+                "prev_run_time": {
+                    "description": "上一次触发时间(任务时区)，本次启动后尚未触发时没有",
+                    "type": "string"
+                },
+                "rerun_interrupted": {
+                    "description": "服务异常退出导致执行中断时，重启后是否重新执行一次",
+                    "type": "boolean",
+                    "example": false
+                },
+                "retry_policy": {
+                    "description": "重试策略，时间单位与请求一致为秒",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.RetryPolicyRequest"
+                        }
+                    ]
+                },
+                "schedule_type": {
+                    "description": "调度方式(cron/at/every)",
+                    "type": "string",
+                    "example": "cron"
+                },
+                "scheduled_time": {
+                    "description": "调度描述",
+                    "type": "string",
+                    "example": "0 2 * * * *"
+                },
+                "status": {
+                    "description": "任务状态(active/paused/completed)",
+                    "type": "string",
+                    "example": "active"
+                },
+                "task_name": {
+                    "description": "任务名称",
+                    "type": "string",
+                    "example": "daily-backup"
+                },
+                "time_zone": {
+                    "description": "时区，为空表示服务器本地时区",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "trigger_rule": {
+                    "description": "上游完成后触发本任务的规则",
+                    "type": "string",
+                    "example": "all_success"
+                },
+                "upstream_task_ids": {
+                    "description": "上游任务ID，非空时由工作流触发",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controller.UpdateShellTaskRequest": {
+            "description": "更新Shell任务的请求参数，所有字段整体替换",
             "type": "object",
             "required": [
-                "command",
                 "description",
-                "scheduled_time",
+                "task_id",
                 "task_name",
-                "timeout"
+                "upstream_task_ids"
             ],
             "properties": {
                 "args": {
@@ -622,162 +2455,211 @@ const docTemplate = `{
                     "type": "string",
                     "example": "./backup.sh"
                 },
+                "concurrency_policy": {
+                    "description": "重叠执行策略(allow/forbid/replace/queue)，默认allow",
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "forbid",
+                        "replace",
+                        "queue"
+                    ],
+                    "example": "forbid"
+                },
                 "description": {
                     "description": "任务描述",
                     "type": "string",
                     "example": "每日数据备份任务"
                 },
+                "env": {
+                    "description": "环境变量，最多50个，值中可通过 ${secret:NAME} 引用密钥",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "DB_PASSWORD": "${secret:db_password}"
+                    }
+                },
+                "limits": {
+                    "description": "资源限制，不传表示使用用户的默认限制",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.ResourceLimitsRequest"
+                        }
+                    ]
+                },
+                "misfire_limit": {
+                    "description": "run_all 策略最多补执行的次数，默认10",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "misfire_policy": {
+                    "description": "服务停止或任务暂停期间错过触发时间时的处理策略(skip/run_once/run_all)，默认skip",
+                    "type": "string",
+                    "enum": [
+                        "skip",
+                        "run_once",
+                        "run_all"
+                    ],
+                    "example": "run_once"
+                },
+                "rerun_interrupted": {
+                    "description": "服务异常退出导致执行中断时，重启后是否重新执行一次，默认false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "retry": {
+                    "description": "重试策略，不传表示不重试",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.RetryPolicyRequest"
+                        }
+                    ]
+                },
+                "schedule_type": {
+                    "description": "调度方式(cron/at/every)，默认cron",
+                    "type": "string",
+                    "enum": [
+                        "cron",
+                        "at",
+                        "every"
+                    ],
+                    "example": "cron"
+                },
                 "scheduled_time": {
-                    "description": "Cron表达式(支持秒级)",
+                    "description": "调度描述：cron表达式(支持秒级)、at的执行时间(如2026-11-01 03:00:00)或every的间隔(如90s、1h+15m)，设置上游任务时忽略",
                     "type": "string",
                     "example": "0 2 * * * *"
                 },
+                "task_id": {
+                    "description": "任务ID",
+                    "type": "string",
+                    "example": "12345"
+                },
                 "task_name": {
                     "description": "任务名称",
                     "type": "string",
                     "example": "daily-backup"
                 },
+                "time_zone": {
+                    "description": "IANA时区名，默认服务器本地时区",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
                 "timeout": {
                     "description": "超时时间(秒)，最大2小时",
                     "type": "integer",
-                    "maximum": 7200,
                     "example": 1800
                 },
-                "use_shell": {
-                    "description": "是否使用Shell",
-                    "type": "boolean",
-                    "example": true
-                }
-            }
-        },
-        "controller.AddShellTaskResponseData": {
-            "description": "添加任务成功响应数据",
-            "type": "object",
-            "properties": {
-                "task_id": {
-                    "description": "新创建的任务ID",
-                    "type": "string",
-                    "example": "12345"
-                }
-            }
-        },
-        "controller.DeleteFileRequest": {
-            "type": "object",
-            "properties": {
-                "file_name": {
-                    "description": "文件名",
-                    "type": "string",
-                    "example": "1699123456789-script.sh"
-                }
-            }
-        },
-        "controller.DeleteTaskRequest": {
-            "description": "删除任务的请求参数",
-            "type": "object",
-            "required": [
-                "task_id"
-            ],
-            "properties": {
-                "task_id": {
-                    "description": "任务ID",
+                "trigger_rule": {
+                    "description": "触发规则(all_success/any_failed/all_done)，默认all_success",
                     "type": "string",
-                    "example": "12345"
-                }
-            }
-        },
-        "controller.ListFilesResponseData": {
-            "type": "object",
-            "properties": {
-                "files": {
+                    "enum": [
+                        "all_success",
+                        "any_failed",
+                        "all_done"
+                    ],
+                    "example": "all_success"
+                },
+                "upstream_task_ids": {
+                    "description": "上游任务ID，设置后任务不再定时触发，而是在上游结束后由工作流触发",
                     "type": "array",
+                    "maxItems": 20,
                     "items": {
                         "type": "string"
-                    }
-                }
-            }
-        },
-        "controller.ListTaskLogResponseData": {
-            "type": "object",
-            "properties": {
-                "list": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.TaskLog"
-                    }
+                    },
+                    "example": [
+                        "task_1699123456789"
+                    ]
                 },
-                "total": {
-                    "type": "integer",
-                    "example": 100
-                }
-            }
-        },
-        "controller.ListTaskResponseData": {
-            "description": "任务列表响应数据结构",
-            "type": "object",
-            "properties": {
-                "tasks": {
-                    "description": "任务列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.TaskResponse"
-                    }
+                "use_shell": {
+                    "description": "是否使用Shell",
+                    "type": "boolean",
+                    "example": true
                 }
             }
         },
-        "controller.LoginRequest": {
+        "controller.UpdateTaskRequest": {
+            "description": "通用的任务更新参数，所有字段整体替换，job_type 必须与任务原有的类型一致",
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "description",
+                "job",
+                "job_type",
+                "task_name",
+                "upstream_task_ids"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
+                "concurrency_policy": {
+                    "description": "重叠执行策略(allow/forbid/replace/queue)，默认allow",
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "forbid",
+                        "replace",
+                        "queue"
+                    ],
+                    "example": "forbid"
                 },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "controller.LoginResponseData": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string"
-                }
-            }
-        },
-        "controller.TaskResponse": {
-            "description": "任务信息响应结构",
-            "type": "object",
-            "properties": {
                 "description": {
                     "description": "任务描述",
                     "type": "string",
                     "example": "每日数据备份任务"
                 },
-                "id": {
-                    "description": "任务ID",
-                    "type": "string",
-                    "example": "12345"
-                },
                 "job": {
-                    "description": "任务详情(JSON格式)",
-                    "type": "string",
-                    "example": "{\"command\":\"/bin/bash\",\"args\":[\"backup.sh\"]}"
+                    "description": "任务详情，结构见对应任务类型的 JSON Schema",
+                    "type": "object"
                 },
                 "job_type": {
                     "description": "任务类型",
                     "type": "string",
                     "example": "shell"
                 },
-                "owner_name": {
-                    "description": "任务拥有者",
+                "misfire_limit": {
+                    "description": "run_all 策略最多补执行的次数，默认10",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "misfire_policy": {
+                    "description": "服务停止或任务暂停期间错过触发时间时的处理策略(skip/run_once/run_all)，默认skip",
                     "type": "string",
-                    "example": "admin"
+                    "enum": [
+                        "skip",
+                        "run_once",
+                        "run_all"
+                    ],
+                    "example": "run_once"
+                },
+                "rerun_interrupted": {
+                    "description": "服务异常退出导致执行中断时，重启后是否重新执行一次，默认false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "retry": {
+                    "description": "重试策略，不传表示不重试",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controller.RetryPolicyRequest"
+                        }
+                    ]
+                },
+                "schedule_type": {
+                    "description": "调度方式(cron/at/every)，默认cron",
+                    "type": "string",
+                    "enum": [
+                        "cron",
+                        "at",
+                        "every"
+                    ],
+                    "example": "cron"
                 },
                 "scheduled_time": {
-                    "description": "Cron表达式",
+                    "description": "调度描述：cron表达式(支持秒级)、at的执行时间(如2026-11-01 03:00:00)或every的间隔(如90s、1h+15m)，设置上游任务时忽略",
                     "type": "string",
                     "example": "0 2 * * * *"
                 },
@@ -785,6 +2667,32 @@ const docTemplate = `{
                     "description": "任务名称",
                     "type": "string",
                     "example": "daily-backup"
+                },
+                "time_zone": {
+                    "description": "IANA时区名，默认服务器本地时区",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "trigger_rule": {
+                    "description": "触发规则(all_success/any_failed/all_done)，默认all_success",
+                    "type": "string",
+                    "enum": [
+                        "all_success",
+                        "any_failed",
+                        "all_done"
+                    ],
+                    "example": "all_success"
+                },
+                "upstream_task_ids": {
+                    "description": "上游任务ID，设置后任务不再定时触发，而是在上游结束后由工作流触发",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task_1699123456789"
+                    ]
                 }
             }
         },
@@ -799,21 +2707,70 @@ const docTemplate = `{
                 }
             }
         },
+        "model.ResourceUsage": {
+            "type": "object",
+            "properties": {
+                "in_blocks": {
+                    "description": "块设备读次数",
+                    "type": "integer"
+                },
+                "involuntary_ctx_switches": {
+                    "description": "被动上下文切换次数",
+                    "type": "integer"
+                },
+                "max_rss_kb": {
+                    "description": "最大常驻内存，单位:KB",
+                    "type": "integer"
+                },
+                "out_blocks": {
+                    "description": "块设备写次数",
+                    "type": "integer"
+                },
+                "system_cpu_ms": {
+                    "description": "内核态 CPU 时间，单位:毫秒",
+                    "type": "integer"
+                },
+                "user_cpu_ms": {
+                    "description": "用户态 CPU 时间，单位:毫秒",
+                    "type": "integer"
+                },
+                "voluntary_ctx_switches": {
+                    "description": "主动上下文切换次数",
+                    "type": "integer"
+                }
+            }
+        },
         "model.TaskLog": {
             "type": "object",
             "properties": {
+                "attempt": {
+                    "description": "第几次尝试，从1开始",
+                    "type": "integer"
+                },
                 "content": {
                     "description": "任务内容，比如 shell 命令或者 Go 函数描述",
                     "type": "string"
                 },
                 "endTime": {
-                    "description": "任务结束时间",
+                    "description": "任务结束时间，执行中的记录与开始时间相同",
                     "type": "string"
                 },
                 "errOutput": {
                     "description": "任务执行错误输出",
                     "type": "string"
                 },
+                "errOutputFile": {
+                    "description": "错误输出超过上限被截断时，完整输出的压缩文件名",
+                    "type": "string"
+                },
+                "exitCode": {
+                    "description": "进程退出码，未能获取时为 -1",
+                    "type": "integer"
+                },
+                "heartbeatAt": {
+                    "description": "执行中定期更新的心跳时间，服务异常退出后据此推断中断时间",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -825,12 +2782,44 @@ const docTemplate = `{
                     "description": "任务执行输出",
                     "type": "string"
                 },
+                "outputFile": {
+                    "description": "输出超过上限被截断时，完整输出的压缩文件名(位于输出目录下)",
+                    "type": "string"
+                },
+                "parentRunId": {
+                    "description": "重试时指向首次执行的运行ID，恢复执行时指向被中断的运行ID",
+                    "type": "string"
+                },
+                "runId": {
+                    "description": "单次执行的唯一ID",
+                    "type": "string"
+                },
                 "startTime": {
                     "description": "任务开始时间",
                     "type": "string"
                 },
+                "status": {
+                    "description": "执行状态",
+                    "type": "string"
+                },
                 "taskId": {
                     "type": "string"
+                },
+                "trigger": {
+                    "description": "触发来源(cron/manual/workflow/recovery/catch_up)",
+                    "type": "string"
+                },
+                "usage": {
+                    "description": "Usage 任务进程的资源使用统计，没有启动进程的任务各项为0",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourceUsage"
+                        }
+                    ]
+                },
+                "workflowRunId": {
+                    "description": "所属工作流运行ID，不属于工作流时为空",
+                    "type": "string"
                 }
             }
         },
@@ -845,6 +2834,77 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "scheduler.HealthInfo": {
+            "type": "object",
+            "properties": {
+                "running": {
+                    "description": "等待或正在执行的任务数",
+                    "type": "integer",
+                    "example": 2
+                },
+                "status": {
+                    "description": "ok 正常，draining 正在停止并等待任务结束",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "scheduler.RunningInfo": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "err_output": {
+                    "type": "string"
+                },
+                "output": {
+                    "description": "最近的输出，只在查询单次运行时返回",
+                    "type": "string"
+                },
+                "owner_name": {
+                    "type": "string"
+                },
+                "pid": {
+                    "description": "进程ID，非进程类任务或尚未启动时为 0",
+                    "type": "integer"
+                },
+                "run_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/running",
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "trigger": {
+                    "type": "string"
+                },
+                "workflow_run_id": {
+                    "description": "所属工作流运行ID",
+                    "type": "string"
+                }
+            }
+        },
+        "scheduler.ScheduleDescription": {
+            "type": "object",
+            "properties": {
+                "en": {
+                    "type": "string"
+                },
+                "zh": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -18,11 +18,19 @@ func NewTaskIDGenerator() TaskIDGenerator {
 }
 
 type singleNodeGenerator struct {
-	mu sync.Mutex
+	mu   sync.Mutex
+	last int64
 }
 
 func (t *singleNodeGenerator) Generate(prefix string) string {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return prefix + fmt.Sprintf("%v", time.Now().UnixMilli())
+
+	// 同一毫秒内多次生成时递增，保证单节点内ID唯一
+	now := time.Now().UnixMilli()
+	if now <= t.last {
+		now = t.last + 1
+	}
+	t.last = now
+	return prefix + fmt.Sprintf("%v", now)
 }
@@ -21,6 +21,13 @@ const (
 	FileNumberLimitCode
 	SearchFailedCode
 	TaskRunFailedCode
+	PauseTaskFailedCode
+	ResumeTaskFailedCode
+	UpdateTaskFailedCode
+	CancelRunFailedCode
+	SaveSecretFailedCode
+	DeleteSecretFailedCode
+	SecretNotConfiguredCode
 )
 
 const (
@@ -39,4 +46,11 @@ const (
 	FileNumberLimitMsg             = "file number limit exceeded"
 	SearchFailedMsg                = "search failed"
 	TaskRunFailedMsg               = "task run failed"
+	PauseTaskFailedMsg             = "pause task failed"
+	ResumeTaskFailedMsg            = "resume task failed"
+	UpdateTaskFailedMsg            = "update task failed"
+	CancelRunFailedMsg             = "cancel run failed"
+	SaveSecretFailedMsg            = "save secret failed"
+	DeleteSecretFailedMsg          = "delete secret failed"
+	SecretNotConfiguredMsg         = "secret store not configured"
 )
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// 同一任务多次执行重叠时的处理策略
+const (
+	ConcurrencyAllow   = "allow"   // 允许并行执行
+	ConcurrencyForbid  = "forbid"  // 上一次未结束时跳过本次执行
+	ConcurrencyReplace = "replace" // 取消上一次执行后再执行本次
+	ConcurrencyQueue   = "queue"   // 等待上一次结束后再执行本次，排队的执行按触发顺序依次开始
+)
+
+const SkippedDueToOverlapMsg = "skipped due to overlap"
+
+func validateConcurrencyPolicy(policy string) error {
+	switch policy {
+	case "", ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace, ConcurrencyQueue:
+		return nil
+	default:
+		return fmt.Errorf("concurrency policy %q unknown", policy)
+	}
+}
+
+// abortedResult 构造未真正执行的结果
+func abortedResult(rs *RunState, status, msg string) TaskResult {
+	now := time.Now()
+	return TaskResult{
+		RunID:         rs.RunID,
+		ParentRunID:   rs.ParentRunID,
+		Attempt:       rs.Attempt,
+		WorkflowRunID: rs.WorkflowRunID,
+		Trigger:       rs.Trigger,
+		Status:        status,
+		ExitCode:      -1,
+		StartTime:     now,
+		EndTime:       now,
+		ErrOutput:     msg,
+	}
+}
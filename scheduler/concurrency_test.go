@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConcurrencyTask(t *testing.T, policy, command string) Task {
+	return NewTask("task_"+policy, policy, "tester", "* * * * *", "",
+		NewShellJob(true, 10*time.Second, t.TempDir(), "tester", command), WithConcurrencyPolicy(policy))
+}
+
+func TestCronScheduler_ConcurrencyForbid(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	defer s.Stop()
+
+	task := newConcurrencyTask(t, ConcurrencyForbid, "sleep 10")
+	first, err := s.RunTask(task)
+	assert.NoError(t, err)
+	second, err := s.RunTask(task)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return store.status(second) == model.RunStatusSkipped
+	}, 2*time.Second, 10*time.Millisecond)
+	l, _ := store.find(second)
+	assert.Equal(t, SkippedDueToOverlapMsg, l.ErrOutput)
+
+	assert.NoError(t, s.CancelRun("tester", first))
+	assert.Eventually(t, func() bool {
+		return store.status(first) == model.RunStatusCancelled
+	}, 3*time.Second, 20*time.Millisecond)
+}
+
+func TestCronScheduler_ConcurrencyQueue(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	defer s.Stop()
+
+	task := newConcurrencyTask(t, ConcurrencyQueue, "sleep 0.3")
+	var runIDs []string
+	for i := 0; i < 3; i++ {
+		runID, err := s.RunTask(task)
+		assert.NoError(t, err)
+		runIDs = append(runIDs, runID)
+	}
+
+	// 排队中的执行保持 pending，不占用协程池，也不写入 running 日志
+	assert.Eventually(t, func() bool {
+		_, ok := store.find(runIDs[0])
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	info, ok := s.GetRunning("tester", runIDs[1])
+	assert.True(t, ok)
+	assert.Equal(t, RunningStatusPending, info.Status)
+	assert.Equal(t, 1, s.pool.Running())
+	_, ok = store.find(runIDs[1])
+	assert.False(t, ok)
+
+	// 排队时被取消的执行直接结束，不影响后面排队的执行
+	assert.NoError(t, s.CancelRun("tester", runIDs[1]))
+	assert.Eventually(t, func() bool {
+		return store.status(runIDs[1]) == model.RunStatusCancelled
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return store.status(runIDs[2]) == model.RunStatusSucceeded && s.Health().Running == 0
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, model.RunStatusSucceeded, store.status(runIDs[0]))
+	first, _ := store.find(runIDs[0])
+	last, _ := store.find(runIDs[2])
+	assert.False(t, last.StartTime.Before(first.EndTime))
+}
+
+func TestCronScheduler_ConcurrencyReplace(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	defer s.Stop()
+
+	task := newConcurrencyTask(t, ConcurrencyReplace, "sleep 1")
+	first, err := s.RunTask(task)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		info, ok := s.GetRunning("tester", first)
+		return ok && info.Status == RunningStatusRunning
+	}, time.Second, 10*time.Millisecond)
+
+	second, err := s.RunTask(task)
+	assert.NoError(t, err)
+	third, err := s.RunTask(task)
+	assert.NoError(t, err)
+
+	// 之前开始的执行被取消，排队中的执行被更新的执行替换
+	assert.Eventually(t, func() bool {
+		return store.status(third) == model.RunStatusSucceeded
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, model.RunStatusCancelled, store.status(first))
+	assert.Equal(t, model.RunStatusCancelled, store.status(second))
+}
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"github.com/chencheng8888/GoDo/dao/model"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/chencheng8888/GoDo/config"
 	"github.com/chencheng8888/GoDo/dao"
+	"github.com/chencheng8888/GoDo/pkg"
+	"github.com/chencheng8888/GoDo/pkg/id_generator"
 	"github.com/chencheng8888/GoDo/pkg/log"
 	"github.com/panjf2000/ants/v2"
 	"github.com/robfig/cron/v3"
@@ -23,8 +27,9 @@ func (c CronJobFunc) Run() {
 }
 
 type CronScheduler struct {
-	c      *cron.Cron
-	parser cron.Parser
+	c           *cron.Cron
+	parser      cron.Parser
+	withSeconds bool
 
 	// 维护 业务ID -> 运行时EntryID 的映射
 	mapping map[string]cron.EntryID
@@ -35,15 +40,62 @@ type CronScheduler struct {
 
 	log         *zap.SugaredLogger
 	taskInfoDao *dao.TaskInfoDao
+	taskLogDao  *dao.TaskLogDao
+
+	taskLog       *TaskLogMiddleware
+	taskLogWriter *TaskLogWriter
 
 	pool *ants.Pool
 
+	runIDGenerator id_generator.TaskIDGenerator
+
+	registry *RunRegistry
+
+	workflows *workflowTracker
+
 	schedulerCtx context.Context
 
-	cancelFunc context.CancelFunc
+	cancelFunc context.CancelCauseFunc
+
+	// 停止时的排空状态，draining 后不再接受新的执行，runs 记录等待或正在执行的任务
+	runMu        sync.Mutex
+	draining     bool
+	runs         sync.WaitGroup
+	runCount     atomic.Int64
+	drainTimeout time.Duration
+
+	// heartbeatInterval 执行中的任务日志更新心跳时间的间隔
+	heartbeatInterval time.Duration
+
+	// outputDir 溢出文件目录，为空时不需要清理；outputRetention 溢出文件的保留时间
+	outputDir       string
+	outputRetention time.Duration
 }
 
-func NewCronScheduler(conf *config.ScheduleConfig, logMiddleware *LogMiddleware, taskLogMiddleware *TaskLogMiddleware, taskInfoDao *dao.TaskInfoDao, logger *zap.SugaredLogger) (*CronScheduler, error) {
+const (
+	// DefaultDrainTimeout 停止时等待正在执行的任务结束的默认时间
+	DefaultDrainTimeout = 30 * time.Second
+	// DefaultHeartbeatInterval 执行中的任务日志更新心跳时间的默认间隔
+	DefaultHeartbeatInterval = 30 * time.Second
+)
+
+// ErrSchedulerStopped 调度器正在停止，不再接受新的执行
+var ErrSchedulerStopped = errors.New("scheduler is shutting down")
+
+// HealthInfo 调度器状态
+type HealthInfo struct {
+	Status  string `json:"status" example:"ok"` // ok 正常，draining 正在停止并等待任务结束
+	Running int64  `json:"running" example:"2"` // 等待或正在执行的任务数
+}
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDraining = "draining"
+)
+
+func NewCronScheduler(conf *config.ScheduleConfig, logMiddleware *LogMiddleware, taskLogMiddleware *TaskLogMiddleware,
+	secretMiddleware *SecretMiddleware, limitMiddleware *LimitMiddleware, runAsMiddleware *RunAsMiddleware, taskLogWriter *TaskLogWriter, registry *RunRegistry, taskInfoDao *dao.TaskInfoDao, taskLogDao *dao.TaskLogDao, generator id_generator.TaskIDGenerator,
+	logger *zap.SugaredLogger) (*CronScheduler, error) {
 
 	parser := cron.NewParser(
 		cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
@@ -62,20 +114,54 @@ func NewCronScheduler(conf *config.ScheduleConfig, logMiddleware *LogMiddleware,
 		return nil, err
 	}
 
-	executor := Chain(BaseExecutor, logMiddleware.Handler, taskLogMiddleware.Handler)
+	// 每次尝试单独经过执行链，重试由调度器在尝试结束后安排
+	executor := Chain(BaseExecutor, logMiddleware.Handler, taskLogMiddleware.Handler, secretMiddleware.Handler, limitMiddleware.Handler, runAsMiddleware.Handler)
+
+	if conf.OutputDir != "" {
+		if err := pkg.CreateDirIfNotExist(conf.OutputDir); err != nil {
+			return nil, err
+		}
+	}
+
+	schedulerCtx := WithKillGracePeriod(context.Background(), time.Duration(conf.KillGracePeriod)*time.Second)
+	schedulerCtx = WithOutputLimits(schedulerCtx, conf.OutputLimit<<10, conf.OutputDir)
+	schedulerCtx, cancel := context.WithCancelCause(schedulerCtx)
 
-	schedulerCtx, cancel := context.WithCancel(context.Background())
+	drainTimeout := time.Duration(conf.DrainTimeout) * time.Second
+	if drainTimeout <= 0 {
+		drainTimeout = DefaultDrainTimeout
+	}
+	heartbeatInterval := time.Duration(conf.HeartbeatInterval) * time.Second
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = DefaultHeartbeatInterval
+	}
+	outputRetention := time.Duration(conf.OutputRetention) * 24 * time.Hour
+	if outputRetention <= 0 {
+		outputRetention = DefaultOutputRetention
+	}
 
 	s := &CronScheduler{
-		c:            c,
-		parser:       parser,
-		mapping:      make(map[string]cron.EntryID),
-		executor:     executor,
-		log:          logger,
-		taskInfoDao:  taskInfoDao,
-		pool:         pool,
-		schedulerCtx: schedulerCtx,
-		cancelFunc:   cancel,
+		c:              c,
+		parser:         parser,
+		withSeconds:    conf.WithSeconds,
+		mapping:        make(map[string]cron.EntryID),
+		executor:       executor,
+		log:            logger,
+		taskInfoDao:    taskInfoDao,
+		taskLogDao:     taskLogDao,
+		pool:           pool,
+		runIDGenerator: generator,
+		registry:       registry,
+		taskLog:        taskLogMiddleware,
+		taskLogWriter:  taskLogWriter,
+		workflows:      newWorkflowTracker(),
+		schedulerCtx:   schedulerCtx,
+		cancelFunc:     cancel,
+		drainTimeout:   drainTimeout,
+
+		heartbeatInterval: heartbeatInterval,
+		outputDir:         conf.OutputDir,
+		outputRetention:   outputRetention,
 	}
 
 	return s, nil
@@ -94,13 +180,28 @@ func (s *CronScheduler) addTask(t Task, addCronOnly bool) error {
 		return fmt.Errorf("task id %s already exists", t.GetID())
 	}
 
-	// 解析时间是否正确
-	sche, err := s.parser.Parse(t.GetScheduledTime())
-	if err != nil {
-		return fmt.Errorf("parse scheduled_time failed: %s", err)
+	var (
+		sche cron.Schedule
+		err  error
+	)
+	// 依赖上游的任务由工作流触发，不需要调度描述
+	if !t.IsDownstream() {
+		// 解析时间是否正确
+		sche, err = parseSchedule(t.GetScheduleType(), t.GetScheduledTime(), t.GetTimeZone(), s.parser)
+		if err != nil {
+			return fmt.Errorf("parse scheduled_time failed: %s", err)
+		}
+	}
+
+	if err = t.validate(); err != nil {
+		return err
 	}
 
 	if !addCronOnly {
+		if err = s.checkUpstreams(t); err != nil {
+			return err
+		}
+
 		// 添加数据库失败
 		err = s.taskInfoDao.CreateTaskInfo(newModel(t))
 		if err != nil {
@@ -111,19 +212,130 @@ func (s *CronScheduler) addTask(t Task, addCronOnly bool) error {
 		}
 	}
 
+	s.workflows.setUpstreams(t.GetID(), t.GetUpstreams())
+	if !t.IsDownstream() {
+		s.schedule(t, sche)
+	}
+
+	s.log.Infof("add a task successfully: %+v", t)
+	return nil
+}
+
+// checkUpstreams 校验上游任务存在且属于同一用户，并且加入依赖后不会成环
+func (s *CronScheduler) checkUpstreams(t Task) error {
+	for _, up := range t.GetUpstreams() {
+		_, err := s.taskInfoDao.GetTaskInfo(t.GetOwnerName(), up)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("upstream task id %s not found", up)
+			}
+			return err
+		}
+	}
+	return s.workflows.checkCycle(t.GetID(), t.GetUpstreams())
+}
+
+// schedule 将任务注册到 cron 中并更新 mapping，调用方需持有 s.mu
+func (s *CronScheduler) schedule(t Task, sche cron.Schedule) {
+	fires := newFireTracker(time.Now())
 	id := s.c.Schedule(sche, CronJobFunc(func() {
-		err := s.pool.Submit(func() {
-			s.executor(s.schedulerCtx, t)
-		})
+		now := time.Now()
+		if err := s.taskInfoDao.UpdateLastFireTime(t.GetID(), now); err != nil {
+			s.log.Errorf("update last fire time of task[%s] failed: %v", t.GetID(), err)
+		}
+		// 进程停顿或系统休眠后 cron 只触发一次，期间跳过的触发按任务的策略补执行
+		missed, more := fires.fire(sche, now, misfireScanLimit(t))
+		s.catchUp(t, missed, more)
+
+		_, err := s.submit(t, model.TriggerCron)
 		if err != nil {
 			s.log.Errorf("submit task to pool failed: %s,task:%v", err, t)
 		}
+		if t.GetScheduleType() == ScheduleTypeAt {
+			s.completeTask(t)
+		}
 	}))
 
 	// 更新mapping
 	s.mapping[t.GetID()] = id
+}
 
-	s.log.Infof("add a task successfully: %+v", t)
+// completeTask 单次执行的任务触发后将其标记为已完成并移出 cron
+func (s *CronScheduler) completeTask(t Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.taskInfoDao.UpdateTaskStatus(t.GetOwnerName(), t.GetID(), model.TaskStatusCompleted)
+	if err != nil {
+		s.log.Errorf("complete task (user_name=%s and task_id=%s) error: %s", t.GetOwnerName(), t.GetID(), err)
+	}
+	s.unschedule(t.GetID())
+	s.log.Infof("one-shot task completed: user_name=%s, task_id=%s", t.GetOwnerName(), t.GetID())
+}
+
+// unschedule 从 cron 中移除任务并更新 mapping，调用方需持有 s.mu
+func (s *CronScheduler) unschedule(taskId string) {
+	cronId, ok := s.mapping[taskId]
+	if !ok {
+		return
+	}
+	s.c.Remove(cronId)
+	delete(s.mapping, taskId)
+}
+
+// UpdateTask 原地更新任务定义，保持任务ID不变并重新注册 cron 调度项，已有的任务日志不受影响
+func (s *CronScheduler) UpdateTask(t Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taskInfo, err := s.taskInfoDao.GetTaskInfo(t.GetOwnerName(), t.GetID())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("task id %s not found", t.GetID())
+		}
+		return err
+	}
+
+	// 更新只修改任务的内容，不能通过其他类型的更新接口把任务改成另一种类型
+	if taskInfo.JobType != t.GetJob().Type() {
+		return fmt.Errorf("task %s is a %s task, can not update it as a %s task", t.GetID(), taskInfo.JobType, t.GetJob().Type())
+	}
+
+	var sche cron.Schedule
+	if !t.IsDownstream() {
+		sche, err = parseSchedule(t.GetScheduleType(), t.GetScheduledTime(), t.GetTimeZone(), s.parser)
+		if err != nil {
+			return fmt.Errorf("parse scheduled_time failed: %s", err)
+		}
+	}
+
+	if err = t.validate(); err != nil {
+		return err
+	}
+
+	if err = s.checkUpstreams(t); err != nil {
+		return err
+	}
+
+	// 更新不改变任务的暂停状态，已完成的单次任务更新后重新生效
+	t.status = taskInfo.Status
+	if t.status == "" || t.status == model.TaskStatusCompleted {
+		t.status = model.TaskStatusActive
+	}
+
+	err = s.taskInfoDao.UpdateTaskInfo(newModel(t))
+	if err != nil {
+		s.log.Errorf("update task info (user_name=%s and task_id=%s) error: %s", t.GetOwnerName(), t.GetID(), err)
+		return err
+	}
+
+	s.workflows.setUpstreams(t.GetID(), t.GetUpstreams())
+	s.unschedule(t.GetID())
+	if !t.IsPaused() && !t.IsDownstream() {
+		s.schedule(t, sche)
+	}
+
+	s.log.Infof("update a task successfully: %+v", t)
 	return nil
 }
 
@@ -151,9 +363,15 @@ func (s *CronScheduler) RemoveTask(userName string, taskId string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	cronId, ok := s.mapping[taskId]
-	if !ok {
-		return fmt.Errorf("task id %s not found", taskId)
+	// 先确认任务属于该用户，避免通过下游检查得知其他用户的任务是否存在
+	if _, err := s.taskInfoDao.GetTaskInfo(userName, taskId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("task id %s not found", taskId)
+		}
+		return err
+	}
+	if downstreams := s.workflows.downstreams(taskId); len(downstreams) > 0 {
+		return fmt.Errorf("task id %s is upstream of %v, remove or update them first", taskId, downstreams)
 	}
 
 	err := s.taskInfoDao.DeleteTaskInfoByTaskId(userName, taskId)
@@ -162,29 +380,220 @@ func (s *CronScheduler) RemoveTask(userName string, taskId string) error {
 		return err
 	}
 
-	s.c.Remove(cronId)
-	delete(s.mapping, taskId)
+	// 暂停中的任务不在 mapping 中，只需删除数据库记录
+	s.unschedule(taskId)
+	s.workflows.setUpstreams(taskId, nil)
 
 	s.log.Infof("delete a task: user_name=%s, task_id=%s", userName, taskId)
 	return nil
 }
 
+// PauseTask 暂停任务：移除 cron 中的调度项，但保留数据库中的任务定义
+func (s *CronScheduler) PauseTask(userName string, taskId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taskInfo, err := s.taskInfoDao.GetTaskInfo(userName, taskId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("task id %s not found", taskId)
+		}
+		return err
+	}
+
+	if taskInfo.IsPaused() {
+		return fmt.Errorf("task id %s is already paused", taskId)
+	}
+	if taskInfo.IsCompleted() {
+		return fmt.Errorf("task id %s is already completed", taskId)
+	}
+
+	err = s.taskInfoDao.UpdateTaskStatus(userName, taskId, model.TaskStatusPaused)
+	if err != nil {
+		s.log.Errorf("pause task (user_name=%s and task_id=%s) error: %s", userName, taskId, err)
+		return err
+	}
+
+	s.unschedule(taskId)
+
+	s.log.Infof("pause a task: user_name=%s, task_id=%s", userName, taskId)
+	return nil
+}
+
+// ResumeTask 恢复已暂停的任务，重新注册 cron 调度项
+func (s *CronScheduler) ResumeTask(userName string, taskId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taskInfo, err := s.taskInfoDao.GetTaskInfo(userName, taskId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("task id %s not found", taskId)
+		}
+		return err
+	}
+
+	if !taskInfo.IsPaused() {
+		return fmt.Errorf("task id %s is not paused", taskId)
+	}
+
+	taskInfo.Status = model.TaskStatusActive
+	task, err := NewTaskFromModel(taskInfo)
+	if err != nil {
+		return err
+	}
+
+	var sche cron.Schedule
+	if !task.IsDownstream() {
+		sche, err = parseSchedule(task.GetScheduleType(), task.GetScheduledTime(), task.GetTimeZone(), s.parser)
+		if err != nil {
+			return fmt.Errorf("parse scheduled_time failed: %s", err)
+		}
+	}
+
+	// 暂停期间错过的触发按任务的策略补执行，执行时间已过的单次任务直接结束
+	now := time.Now()
+	var (
+		missed []time.Time
+		more   bool
+	)
+	if !task.IsDownstream() {
+		missed, more = missedFireTimes(sche, lastFireTime(taskInfo), now, misfireScanLimit(task))
+	}
+	status := model.TaskStatusActive
+	if len(missed) > 0 && task.GetScheduleType() == ScheduleTypeAt {
+		status = model.TaskStatusCompleted
+	}
+
+	err = s.taskInfoDao.UpdateTaskStatus(userName, taskId, status)
+	if err != nil {
+		s.log.Errorf("resume task (user_name=%s and task_id=%s) error: %s", userName, taskId, err)
+		return err
+	}
+
+	s.unschedule(taskId)
+	if !task.IsDownstream() && status == model.TaskStatusActive {
+		s.schedule(task, sche)
+	}
+	if len(missed) > 0 {
+		if err := s.taskInfoDao.UpdateLastFireTime(taskId, now); err != nil {
+			s.log.Errorf("update last fire time of task[%s] failed: %v", taskId, err)
+		}
+		s.catchUp(task, missed, more)
+	}
+
+	s.log.Infof("resume a task: user_name=%s, task_id=%s", userName, taskId)
+	return nil
+}
+
 func (s *CronScheduler) Start() {
 	s.log.Info("🚩Task scheduler start")
 	s.c.Start()
+	go s.heartbeat()
+	if s.outputDir != "" {
+		go s.sweepOutputs()
+	}
+}
+
+// heartbeat 定期更新执行中任务日志的心跳时间，调度器停止后退出
+func (s *CronScheduler) heartbeat() {
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.schedulerCtx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.taskLogWriter.Touch(s.registry.runIDs(), now); err != nil {
+				s.log.Warnf("update heartbeat of running task logs failed: %v", err)
+			}
+		}
+	}
 }
 
+// sweepOutputs 启动时及之后每隔 outputSweepInterval 删除超过保留时间的溢出文件，调度器停止后退出
+func (s *CronScheduler) sweepOutputs() {
+	ticker := time.NewTicker(outputSweepInterval)
+	defer ticker.Stop()
+	for {
+		removed, err := removeExpiredSpools(s.outputDir, time.Now().Add(-s.outputRetention))
+		if err != nil {
+			s.log.Warnf("remove expired output files in %s failed: %v", s.outputDir, err)
+		} else if removed > 0 {
+			s.log.Infof("removed %d expired output files in %s", removed, s.outputDir)
+		}
+
+		select {
+		case <-s.schedulerCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop 排空后停止：不再接受新的执行，等待正在执行的任务结束，超过 drainTimeout 后终止剩余任务并记为 interrupted
 func (s *CronScheduler) Stop() {
-	// 取消上下文
-	s.cancelFunc()
-	// 停止 cron 调度器
-	ctx := s.c.Stop()
-	<-ctx.Done()
+	s.runMu.Lock()
+	s.draining = true
+	s.runMu.Unlock()
+
+	// 停止 cron 调度器，等待正在进行的触发结束；触发只提交执行不等待执行结束，但更新数据库可能变慢，等待时间计入排空期限
+	deadline := time.Now().Add(s.drainTimeout)
+	timer := time.NewTimer(s.drainTimeout)
+	select {
+	case <-s.c.Stop().Done():
+	case <-timer.C:
+		s.log.Warnf("cron jobs did not return within %v", s.drainTimeout)
+	}
+	timer.Stop()
+
+	s.log.Infof("⏳ Task scheduler draining, waiting up to %v for %d runs", time.Until(deadline).Round(time.Millisecond), s.runCount.Load())
+	if !waitTimeout(&s.runs, time.Until(deadline)) {
+		s.log.Warnf("drain timeout, interrupt %d runs", s.runCount.Load())
+		s.cancelFunc(ErrSchedulerStopped)
+		// 等待被终止的任务退出并记录日志
+		if !waitTimeout(&s.runs, killGracePeriodFromContext(s.schedulerCtx)+5*time.Second) {
+			s.log.Errorf("%d runs did not exit after being interrupted", s.runCount.Load())
+		}
+	}
+	s.cancelFunc(ErrSchedulerStopped)
 	// 释放 goroutine 池资源
 	s.pool.Release()
+	// 写入队列中剩余的任务日志
+	s.taskLogWriter.Close()
 	s.log.Info("✔️Task scheduler stopped")
 }
 
+// waitTimeout 等待 wg 结束，超时返回 false
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Health 返回调度器是否正在停止以及等待或正在执行的任务数
+func (s *CronScheduler) Health() HealthInfo {
+	s.runMu.Lock()
+	draining := s.draining
+	s.runMu.Unlock()
+
+	info := HealthInfo{Status: HealthStatusOK, Running: s.runCount.Load()}
+	if draining {
+		info.Status = HealthStatusDraining
+	}
+	return info
+}
+
 func (s *CronScheduler) InitializeTasks() {
 	s.log.Infof("🤖start initialize tasks from db...")
 
@@ -192,23 +601,180 @@ func (s *CronScheduler) InitializeTasks() {
 	if err != nil {
 		s.log.Errorf("initialize tasks:failed to get task info from db: %v", err)
 	}
+	tasks := make(map[string]Task, len(taskInfos))
 	for _, taskInfo := range taskInfos {
 		task, err := NewTaskFromModel(taskInfo)
 		if err != nil {
 			s.log.Errorf("initialize tasks:failed to new task from model[%v]: %v", taskInfo, err)
 			continue
 		}
+		tasks[task.GetID()] = task
+		// 暂停的任务同样保留在依赖图中，工作流运行到它时记为跳过
+		s.workflows.setUpstreams(task.GetID(), task.GetUpstreams())
+	}
+
+	// 先处理上次退出时被中断的执行，再开始调度和补执行，避免把本次启动后开始的执行误认为被中断
+	s.recoverInterruptedRuns(tasks)
+
+	for _, taskInfo := range taskInfos {
+		task, ok := tasks[taskInfo.TaskId]
+		if !ok {
+			continue
+		}
+		if task.IsPaused() || task.IsCompleted() {
+			s.log.Infof("initialize tasks:skip %s task[%s]", task.GetStatus(), task.GetID())
+			continue
+		}
 		err = s.addTask(task, true)
 		if err != nil {
 			s.log.Errorf("initialize tasks:failed to add task[%v]: %v", taskInfo, err)
 			continue
 		}
+		// 服务停止期间错过的触发按任务的策略补执行，执行时间已过的单次任务直接结束
+		if s.catchUpMissed(task, lastFireTime(taskInfo), time.Now()) && task.GetScheduleType() == ScheduleTypeAt {
+			s.completeTask(task)
+		}
 	}
 	s.log.Infof("✅initialize tasks from db finished")
 }
 
-func (s *CronScheduler) RunTask(ctx context.Context, task Task) {
-	s.executor(ctx, task)
+// recoverInterruptedRuns 将上次服务异常退出时仍处于 running 的执行记为 interrupted，
+// 结束时间取最后一次心跳时间；任务开启了 RerunInterrupted 时重新执行一次
+func (s *CronScheduler) recoverInterruptedRuns(tasks map[string]Task) {
+	// 先写入上次退出前暂存在本地的日志，其中可能有这些执行的结束记录
+	s.taskLogWriter.replayAndWait()
+
+	logs, err := s.taskLogDao.FindRunningTaskLogs()
+	if err != nil {
+		s.log.Errorf("initialize tasks:failed to find running task logs: %v", err)
+		return
+	}
+	// HTTP 服务与初始化同时启动，本次启动后已经开始的手动执行不属于被中断的执行
+	current := make(map[string]bool)
+	for _, runID := range s.registry.runIDs() {
+		current[runID] = true
+	}
+	for _, l := range logs {
+		if current[l.RunId] || s.taskLogWriter.hasUnsaved(l.RunId) {
+			continue
+		}
+		endTime := l.HeartbeatAt
+		if endTime.IsZero() {
+			endTime = l.StartTime
+		}
+		errOutput := fmt.Sprintf("interrupted: the server exited while the task was running, last heartbeat at %s", endTime.Format(time.DateTime))
+		if err := s.taskLogDao.InterruptTaskLog(l.ID, endTime, errOutput); err != nil {
+			s.log.Errorf("initialize tasks:failed to mark run %s as interrupted: %v", l.RunId, err)
+			continue
+		}
+		s.log.Warnf("initialize tasks:run %s of task[%s] was interrupted", l.RunId, l.TaskId)
+
+		task, ok := tasks[l.TaskId]
+		// 恢复执行本身被中断时不再重新执行，避免每次重启都反复执行
+		if !ok || !task.GetRerunInterrupted() || task.IsPaused() || l.Trigger == model.TriggerRecovery {
+			continue
+		}
+		e, err := s.newRun(task, model.TriggerRecovery, "")
+		if err != nil {
+			s.log.Errorf("initialize tasks:failed to rerun interrupted run %s: %v", l.RunId, err)
+			continue
+		}
+		e.first.ParentRunID = l.RunId
+		s.dispatch(e)
+		s.log.Infof("initialize tasks:rerun interrupted run %s of task[%s] as %s", l.RunId, l.TaskId, e.first.RunID)
+	}
+}
+
+// RunTask 手动触发任务，与定时触发一样提交到协程池异步执行，立即返回本次执行的运行ID
+func (s *CronScheduler) RunTask(task Task) (string, error) {
+	return s.submit(task, model.TriggerManual)
+}
+
+// submit 为一次执行分配运行ID并异步提交到协程池，不会因协程池已满而阻塞
+func (s *CronScheduler) submit(t Task, trigger string) (string, error) {
+	e, err := s.newRun(t, trigger, s.startWorkflow(t))
+	if err != nil {
+		return "", err
+	}
+	s.dispatch(e)
+	return e.first.RunID, nil
+}
+
+// FireTimes 返回任务下一次和上一次触发的时间(任务所在时区)，任务未被调度或本次启动后尚未触发时对应的值为零值
+func (s *CronScheduler) FireTimes(t Task) (next time.Time, prev time.Time) {
+	s.mu.Lock()
+	cronId, ok := s.mapping[t.GetID()]
+	s.mu.Unlock()
+	if !ok {
+		return time.Time{}, time.Time{}
+	}
+
+	entry := s.c.Entry(cronId)
+	next, prev = entry.Next, entry.Prev
+	if loc, err := loadLocation(t.GetTimeZone()); err == nil {
+		if !next.IsZero() {
+			next = next.In(loc)
+		}
+		if !prev.IsZero() {
+			prev = prev.In(loc)
+		}
+	}
+	return next, prev
+}
+
+// PreviewSchedule 使用与调度器相同的解析配置计算接下来 count 次触发时间，并给出中英文解释
+func (s *CronScheduler) PreviewSchedule(scheduleType, spec, timeZone string, count int) ([]time.Time, ScheduleDescription, error) {
+	sche, err := parseSchedule(scheduleType, spec, timeZone, s.parser)
+	if err != nil {
+		return nil, ScheduleDescription{}, fmt.Errorf("parse scheduled_time failed: %s", err)
+	}
+
+	desc, err := describeSchedule(scheduleType, spec, timeZone, s.withSeconds)
+	if err != nil {
+		return nil, ScheduleDescription{}, fmt.Errorf("describe scheduled_time failed: %s", err)
+	}
+
+	loc, _ := loadLocation(timeZone)
+	times := make([]time.Time, 0, count)
+	next := time.Now()
+	for i := 0; i < count; i++ {
+		next = sche.Next(next)
+		if next.IsZero() {
+			break
+		}
+		times = append(times, next.In(loc))
+	}
+	return times, desc, nil
+}
+
+// ListRunning 列出用户正在执行中的任务
+func (s *CronScheduler) ListRunning(userName string) []RunningInfo {
+	return s.registry.list(userName)
+}
+
+// GetRunning 查询用户等待中或执行中的某次运行
+func (s *CronScheduler) GetRunning(userName string, runId string) (RunningInfo, bool) {
+	return s.registry.get(userName, runId)
+}
+
+// GetUnsavedLog 查询用户某次已结束但任务日志尚未写入数据库的运行
+func (s *CronScheduler) GetUnsavedLog(userName string, runId string) (model.TaskLog, bool) {
+	return s.taskLogWriter.Unsaved(userName, runId)
+}
+
+// SubscribeOutput 订阅用户等待中或执行中的某次运行的实时输出，运行不存在或已结束时返回 false
+func (s *CronScheduler) SubscribeOutput(userName string, runId string) (<-chan OutputLine, func(), bool) {
+	return s.registry.subscribe(userName, runId)
+}
+
+// CancelRun 取消用户正在执行中的某次运行
+func (s *CronScheduler) CancelRun(userName string, runId string) error {
+	err := s.registry.cancel(userName, runId)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("cancel a run: user_name=%s, run_id=%s", userName, runId)
+	return nil
 }
 
 func newModel(task Task) *model.TaskInfo {
@@ -220,5 +786,16 @@ func newModel(task Task) *model.TaskInfo {
 		Description:   task.GetDescription(),
 		JobType:       task.GetJob().Type(),
 		Job:           task.GetJob().ToJson(),
+		Status:        task.GetStatus(),
+		RetryPolicy:   task.GetRetryPolicy().ToJson(),
+		Concurrency:   task.GetConcurrencyPolicy(),
+		ScheduleType:  task.GetScheduleType(),
+		TimeZone:      task.GetTimeZone(),
+		Upstreams:     upstreamsToJson(task.GetUpstreams()),
+		TriggerRule:   task.GetTriggerRule(),
+
+		RerunInterrupted: task.GetRerunInterrupted(),
+		MisfirePolicy:    task.GetMisfirePolicy(),
+		MisfireLimit:     task.GetMisfireLimit(),
 	}
 }
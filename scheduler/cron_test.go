@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/chencheng8888/GoDo/pkg/id_generator"
+	"github.com/panjf2000/ants/v2"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// newTestCronScheduler 构造不依赖数据库的调度器，任务日志写入 store
+func newTestCronScheduler(t *testing.T, store TaskLogStore, drainTimeout time.Duration) *CronScheduler {
+	log := zap.NewNop().Sugar()
+	writer, err := NewTaskLogWriter(&config.ScheduleConfig{TaskLog: &config.TaskLogConfig{
+		FlushInterval: 20,
+		JournalPath:   filepath.Join(t.TempDir(), "task_log.journal"),
+	}}, store, log)
+	assert.NoError(t, err)
+	pool, err := ants.NewPool(4)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancelCause(WithKillGracePeriod(context.Background(), 200*time.Millisecond))
+	taskLog := NewTaskLogMiddleware(log, writer)
+	return &CronScheduler{
+		c:              cron.New(),
+		mapping:        make(map[string]cron.EntryID),
+		executor:       Chain(BaseExecutor, taskLog.Handler),
+		log:            log,
+		taskLog:        taskLog,
+		taskLogWriter:  writer,
+		pool:           pool,
+		runIDGenerator: id_generator.NewTaskIDGenerator(),
+		registry:       NewRunRegistry(),
+		workflows:      newWorkflowTracker(),
+		schedulerCtx:   ctx,
+		cancelFunc:     cancel,
+		drainTimeout:   drainTimeout,
+
+		heartbeatInterval: 50 * time.Millisecond,
+	}
+}
+
+func TestCronScheduler_Drain(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, 500*time.Millisecond)
+	s.Start()
+
+	fast := NewTask("task_fast", "fast", "tester", "* * * * *", "",
+		NewShellJob(true, 10*time.Second, t.TempDir(), "tester", "sleep 0.2"))
+	slow := NewTask("task_slow", "slow", "tester", "* * * * *", "",
+		NewShellJob(true, 10*time.Second, t.TempDir(), "tester", "sleep 10"))
+
+	fastRun, err := s.RunTask(fast)
+	assert.NoError(t, err)
+	slowRun, err := s.RunTask(slow)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return s.Health().Running == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, HealthStatusOK, s.Health().Status)
+
+	start := time.Now()
+	s.Stop()
+	assert.Less(t, time.Since(start), 3*time.Second)
+
+	// 期限内结束的任务正常记录，超过期限的任务被终止并记为 interrupted
+	assert.Equal(t, model.RunStatusSucceeded, store.status(fastRun))
+	assert.Equal(t, model.RunStatusInterrupted, store.status(slowRun))
+
+	assert.Equal(t, HealthInfo{Status: HealthStatusDraining}, s.Health())
+	_, err = s.RunTask(fast)
+	assert.ErrorIs(t, err, ErrSchedulerStopped)
+}
+
+func TestCronScheduler_StopWithBlockedCronJob(t *testing.T) {
+	s := newTestCronScheduler(t, &fakeTaskLogStore{}, 300*time.Millisecond)
+	s.Start()
+
+	fired, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	var once sync.Once
+	s.c.Schedule(cron.Every(time.Second), CronJobFunc(func() {
+		once.Do(func() { close(fired) })
+		<-release
+	}))
+	<-fired
+
+	// 触发卡住时停止也不会超过排空期限太久
+	start := time.Now()
+	s.Stop()
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestCronScheduler_RunningLogHeartbeat(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	s.Start()
+	defer s.Stop()
+
+	task := NewTask("task_slow", "slow", "tester", "* * * * *", "",
+		NewShellJob(true, 10*time.Second, t.TempDir(), "tester", "sleep 0.5"))
+	runID, err := s.RunTask(task)
+	assert.NoError(t, err)
+
+	// 开始执行时写入 running 记录，执行期间定期更新心跳
+	assert.Eventually(t, func() bool {
+		l, ok := store.find(runID)
+		return ok && l.Status == model.RunStatusRunning && l.HeartbeatAt.After(l.StartTime)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 结束时的记录覆盖同一运行ID的 running 记录
+	assert.Eventually(t, func() bool {
+		return store.status(runID) == model.RunStatusSucceeded
+	}, 3*time.Second, 20*time.Millisecond)
+	assert.Len(t, store.savedRuns(), 1)
+}
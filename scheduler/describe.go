@@ -0,0 +1,354 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LangEn = "en"
+	LangZh = "zh"
+)
+
+// ScheduleDescription 调度描述的中英文解释
+type ScheduleDescription struct {
+	En string `json:"en"`
+	Zh string `json:"zh"`
+}
+
+var (
+	monthNames   = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dowNames     = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	monthNamesEn = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	dowNamesEn   = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	dowNamesZh   = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+)
+
+var cronDescriptors = map[string]ScheduleDescription{
+	"@yearly":   {En: "At 00:00 on January 1, every year", Zh: "每年1月1日 00:00"},
+	"@annually": {En: "At 00:00 on January 1, every year", Zh: "每年1月1日 00:00"},
+	"@monthly":  {En: "At 00:00 on day 1 of every month", Zh: "每月1日 00:00"},
+	"@weekly":   {En: "At 00:00 every Sunday", Zh: "每周日 00:00"},
+	"@daily":    {En: "At 00:00 every day", Zh: "每天 00:00"},
+	"@midnight": {En: "At 00:00 every day", Zh: "每天 00:00"},
+	"@hourly":   {En: "At minute 0 of every hour", Zh: "每小时的第0分钟"},
+}
+
+// describeSchedule 生成调度描述的中英文解释，withSeconds 与调度器的 cron 解析配置保持一致
+func describeSchedule(scheduleType, spec, timeZone string, withSeconds bool) (ScheduleDescription, error) {
+	loc, err := loadLocation(timeZone)
+	if err != nil {
+		return ScheduleDescription{}, err
+	}
+
+	var desc ScheduleDescription
+	switch scheduleType {
+	case "", ScheduleTypeCron:
+		desc, err = describeCron(spec, withSeconds)
+	case ScheduleTypeAt:
+		var at AtSchedule
+		at, err = parseAtSchedule(spec, loc)
+		ts := at.At.In(loc).Format("2006-01-02 15:04:05")
+		desc = ScheduleDescription{En: "Once at " + ts, Zh: "在 " + ts + " 执行一次"}
+	case ScheduleTypeEvery:
+		var every EverySchedule
+		every, err = parseEverySchedule(spec, loc)
+		desc = ScheduleDescription{En: "Every " + every.Interval.String(), Zh: "每 " + every.Interval.String() + " 执行一次"}
+		if every.Offset > 0 {
+			desc.En += ", offset by " + every.Offset.String()
+			desc.Zh += "，偏移 " + every.Offset.String()
+		}
+	default:
+		err = fmt.Errorf("schedule type %q unknown", scheduleType)
+	}
+	if err != nil {
+		return ScheduleDescription{}, err
+	}
+
+	if timeZone != "" {
+		desc.En += " (" + timeZone + ")"
+		desc.Zh += "（" + timeZone + "）"
+	}
+	return desc, nil
+}
+
+func describeCron(spec string, withSeconds bool) (ScheduleDescription, error) {
+	spec = strings.TrimSpace(spec)
+
+	var tzSuffix ScheduleDescription
+	if hasTimeZonePrefix(spec) {
+		prefix, rest, _ := strings.Cut(spec, " ")
+		_, tz, _ := strings.Cut(prefix, "=")
+		tzSuffix = ScheduleDescription{En: " (" + tz + ")", Zh: "（" + tz + "）"}
+		spec = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return ScheduleDescription{}, err
+		}
+		return ScheduleDescription{En: "Every " + d.String() + tzSuffix.En, Zh: "每 " + d.String() + " 执行一次" + tzSuffix.Zh}, nil
+	}
+	if desc, ok := cronDescriptors[spec]; ok {
+		return ScheduleDescription{En: desc.En + tzSuffix.En, Zh: desc.Zh + tzSuffix.Zh}, nil
+	}
+
+	fields := strings.Fields(spec)
+	want := 5
+	if withSeconds {
+		want = 6
+	}
+	if len(fields) != want {
+		return ScheduleDescription{}, fmt.Errorf("expected %d fields, found %d: %s", want, len(fields), spec)
+	}
+	if !withSeconds {
+		fields = append([]string{"0"}, fields...)
+	}
+	sec, min, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4], normalizeNames(fields[5], dowNames, 0)
+	month = normalizeNames(month, monthNames, 1)
+
+	fixedClock := isSingle(sec) && isSingle(min) && isSingle(hour)
+	en := describeCronTime(sec, min, hour, LangEn)
+	if day := describeCronDay(dom, month, dow, fixedClock, LangEn); day != "" {
+		en += ", " + day
+	}
+	zh := describeCronTime(sec, min, hour, LangZh)
+	if day := describeCronDay(dom, month, dow, fixedClock, LangZh); day != "" {
+		zh = day + " " + zh
+	}
+	en = strings.ToUpper(en[:1]) + en[1:]
+	return ScheduleDescription{En: en + tzSuffix.En, Zh: zh + tzSuffix.Zh}, nil
+}
+
+// normalizeNames 将 JAN、MON 等名称替换为数字
+func normalizeNames(field string, names []string, start int) string {
+	upper := strings.ToUpper(field)
+	for i := len(names) - 1; i >= start; i-- {
+		if names[i] != "" {
+			upper = strings.ReplaceAll(upper, names[i], strconv.Itoa(i))
+		}
+	}
+	return upper
+}
+
+func isWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+func isSingle(field string) bool {
+	_, err := strconv.Atoi(field)
+	return err == nil
+}
+
+func describeCronTime(sec, min, hour, lang string) string {
+	if isSingle(sec) && isSingle(min) && isSingle(hour) {
+		s, _ := strconv.Atoi(sec)
+		m, _ := strconv.Atoi(min)
+		h, _ := strconv.Atoi(hour)
+		clock := fmt.Sprintf("%02d:%02d", h, m)
+		if s != 0 {
+			clock += fmt.Sprintf(":%02d", s)
+		}
+		if lang == LangZh {
+			return clock
+		}
+		return "at " + clock
+	}
+
+	// 按秒、分、时的顺序收集需要说明的字段，被更细粒度的重复字段隐含的部分省略
+	var parts []string
+	if sec != "0" {
+		parts = append(parts, describeField(sec, "second", lang))
+	}
+	switch {
+	case isWildcard(min):
+		if isSingle(sec) {
+			parts = append(parts, describeField(min, "minute", lang))
+		}
+	case min == "0" && sec == "0" && !isWildcard(hour):
+	case isSingle(min) && isWildcard(hour):
+		if lang == LangZh {
+			parts = append(parts, "每小时"+min+"分")
+		} else {
+			parts = append(parts, "at minute "+min+" of every hour")
+		}
+	default:
+		parts = append(parts, describeField(min, "minute", lang))
+	}
+	if !isWildcard(hour) {
+		parts = append(parts, describeField(hour, "hour", lang))
+	}
+
+	if lang == LangZh {
+		// 中文从大到小描述：小时 -> 分钟 -> 秒
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeCronDay 解释日期相关字段，没有日期限制且时间固定时说明为每天
+func describeCronDay(dom, month, dow string, fixedClock bool, lang string) string {
+	if lang == LangZh {
+		var res string
+		if !isWildcard(month) {
+			res += describeField(month, "month", lang)
+			if !isWildcard(dom) {
+				res += strings.TrimPrefix(describeField(dom, "day", lang), fieldPrefixZh("day"))
+			}
+		} else if !isWildcard(dom) {
+			res += describeField(dom, "day", lang)
+		}
+		if !isWildcard(dow) {
+			res += describeField(dow, "weekday", lang)
+		}
+		if res == "" && fixedClock {
+			return "每天"
+		}
+		return res
+	}
+
+	var parts []string
+	if !isWildcard(dom) {
+		parts = append(parts, describeField(dom, "day", lang))
+	}
+	if !isWildcard(dow) {
+		parts = append(parts, describeField(dow, "weekday", lang))
+	}
+	if !isWildcard(month) {
+		parts = append(parts, describeField(month, "month", lang))
+	}
+	if len(parts) == 0 && fixedClock {
+		return "every day"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeField 解释单个 cron 字段，支持 *、*/n、a、a-b、a-b/n、a/n 以及逗号分隔的列表
+func describeField(field, unit, lang string) string {
+	if isWildcard(field) {
+		if lang == LangZh {
+			return map[string]string{"second": "每秒", "minute": "每分钟", "hour": "每小时"}[unit]
+		}
+		return "every " + unit
+	}
+
+	if strings.Contains(field, ",") {
+		items := strings.Split(field, ",")
+		names := make([]string, 0, len(items))
+		for _, item := range items {
+			names = append(names, fieldValueName(item, unit, lang))
+		}
+		if lang == LangZh {
+			return fieldPrefixZh(unit) + strings.Join(names, "、") + fieldSuffixZh(unit)
+		}
+		return fieldPrefixEn(unit) + strings.Join(names, ", ")
+	}
+
+	rangePart, stepPart, hasStep := strings.Cut(field, "/")
+	if hasStep {
+		from, to, isRange := strings.Cut(rangePart, "-")
+		if lang == LangZh {
+			res := fmt.Sprintf("每%s%s", stepPart, unitNameZh(unit))
+			if rangePart != "*" {
+				if isRange {
+					res = fmt.Sprintf("%s至%s之间", fieldValueName(from, unit, lang)+fieldSuffixZh(unit), fieldValueName(to, unit, lang)+fieldSuffixZh(unit)) + res
+				} else {
+					res = fmt.Sprintf("从%s起", fieldValueName(from, unit, lang)+fieldSuffixZh(unit)) + res
+				}
+			}
+			return res
+		}
+		res := fmt.Sprintf("every %s %ss", stepPart, unit)
+		if rangePart != "*" {
+			if isRange {
+				res += fmt.Sprintf(" between %s and %s", fieldValueName(from, unit, lang), fieldValueName(to, unit, lang))
+			} else {
+				res += fmt.Sprintf(" starting at %s", fieldValueName(from, unit, lang))
+			}
+		}
+		return res
+	}
+
+	if from, to, isRange := strings.Cut(field, "-"); isRange {
+		if lang == LangZh {
+			return fieldPrefixZh(unit) + fieldValueName(from, unit, lang) + "至" + fieldValueName(to, unit, lang) + fieldSuffixZh(unit)
+		}
+		return fieldPrefixEn(unit) + fieldValueName(from, unit, lang) + " through " + fieldValueName(to, unit, lang)
+	}
+
+	if lang == LangZh {
+		return fieldPrefixZh(unit) + fieldValueName(field, unit, lang) + fieldSuffixZh(unit)
+	}
+	return fieldPrefixEn(unit) + fieldValueName(field, unit, lang)
+}
+
+func fieldValueName(value, unit, lang string) string {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return value
+	}
+	switch unit {
+	case "weekday":
+		n %= 7
+		if lang == LangZh {
+			return dowNamesZh[n]
+		}
+		return dowNamesEn[n]
+	case "month":
+		if n >= 1 && n <= 12 && lang == LangEn {
+			return monthNamesEn[n]
+		}
+	}
+	return value
+}
+
+func fieldPrefixEn(unit string) string {
+	switch unit {
+	case "day":
+		return "on day "
+	case "weekday":
+		return "on "
+	case "month":
+		return "in "
+	default:
+		return "at " + unit + " "
+	}
+}
+
+func fieldPrefixZh(unit string) string {
+	switch unit {
+	case "day":
+		return "每月"
+	case "weekday":
+		return "每"
+	default:
+		return ""
+	}
+}
+
+func fieldSuffixZh(unit string) string {
+	return map[string]string{
+		"second": "秒",
+		"minute": "分",
+		"hour":   "点",
+		"day":    "日",
+		"month":  "月",
+	}[unit]
+}
+
+func unitNameZh(unit string) string {
+	return map[string]string{
+		"second":  "秒",
+		"minute":  "分钟",
+		"hour":    "小时",
+		"day":     "天",
+		"weekday": "天",
+		"month":   "个月",
+	}[unit]
+}
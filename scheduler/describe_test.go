@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeSchedule(t *testing.T) {
+	tests := []struct {
+		scheduleType string
+		spec         string
+		timeZone     string
+		withSeconds  bool
+		en           string
+		zh           string
+	}{
+		{ScheduleTypeCron, "0 0 2 * * *", "", true, "At 02:00, every day", "每天 02:00"},
+		{ScheduleTypeCron, "0 */5 * * * *", "", true, "Every 5 minutes", "每5分钟"},
+		{ScheduleTypeCron, "0 0 9 * * MON-FRI", "", true, "At 09:00, on Monday through Friday", "每周一至周五 09:00"},
+		{ScheduleTypeCron, "30 0 12 * * 6,0", "", true, "At 12:00:30, on Saturday, Sunday", "每周六、周日 12:00:30"},
+		{ScheduleTypeCron, "0 30 * * * *", "", true, "At minute 30 of every hour", "每小时30分"},
+		{ScheduleTypeCron, "0 0 0 1 JAN *", "", true, "At 00:00, on day 1, in January", "1月1日 00:00"},
+		{ScheduleTypeCron, "5 4 * * 0", "", false, "At 04:05, on Sunday", "每周日 04:05"},
+		{ScheduleTypeCron, "0 9 * * *", "Asia/Tokyo", false, "At 09:00, every day (Asia/Tokyo)", "每天 09:00（Asia/Tokyo）"},
+		{ScheduleTypeCron, "@daily", "", false, "At 00:00 every day", "每天 00:00"},
+		{ScheduleTypeAt, "2026-11-01 03:00:00", "", false, "Once at 2026-11-01 03:00:00", "在 2026-11-01 03:00:00 执行一次"},
+		{ScheduleTypeEvery, "1h+15m", "", false, "Every 1h0m0s, offset by 15m0s", "每 1h0m0s 执行一次，偏移 15m0s"},
+	}
+	for _, tt := range tests {
+		desc, err := describeSchedule(tt.scheduleType, tt.spec, tt.timeZone, tt.withSeconds)
+		assert.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.en, desc.En, tt.spec)
+		assert.Equal(t, tt.zh, desc.Zh, tt.spec)
+	}
+
+	_, err := describeSchedule(ScheduleTypeCron, "0 0 2 * *", "", true)
+	assert.Error(t, err, "field count must follow the seconds setting")
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+// execution 一次触发对应的执行，包含首次尝试以及按重试策略进行的后续尝试
+// 每次尝试都有独立的运行ID，后续尝试的 ParentRunID 指向首次尝试
+type execution struct {
+	task          Task
+	trigger       string
+	workflowRunID string
+	first         *RunState
+
+	// ctx 被取消后不再开始新的尝试，取消函数同时用于取消等待中和执行中的尝试
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	then func() // 执行结束(不再重试)后调用，用于依次提交多次执行
+}
+
+// newRun 分配首次尝试的运行ID并登记为 pending，调用方设置好运行状态后通过 dispatch 提交
+// workflowRunID 非空时，执行结束后推进所属的工作流；调度器正在停止时返回 ErrSchedulerStopped
+func (s *CronScheduler) newRun(t Task, trigger string, workflowRunID string) (*execution, error) {
+	s.runMu.Lock()
+	if s.draining {
+		s.runMu.Unlock()
+		if workflowRunID != "" {
+			s.advanceWorkflow(workflowRunID, t.GetOwnerName(), t.GetID(), model.RunStatusInterrupted)
+		}
+		return nil, ErrSchedulerStopped
+	}
+	s.runs.Add(1)
+	s.runCount.Add(1)
+	s.runMu.Unlock()
+
+	rs := NewRunState(s.runIDGenerator.Generate(RunIDPrefix), t.GetID(), trigger)
+	rs.WorkflowRunID = workflowRunID
+	ctx, cancel := context.WithCancel(s.schedulerCtx)
+	e := &execution{
+		task:          t,
+		trigger:       trigger,
+		workflowRunID: workflowRunID,
+		first:         rs,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	s.registry.addPending(t, rs, cancel)
+	return e, nil
+}
+
+// dispatch 按任务的并发策略处理首次尝试：立即提交、排队等待同一任务之前的执行结束或直接跳过
+// 排队期间不占用协程池，也不写入 running 日志，执行一直处于 pending 状态，可以被取消
+func (s *CronScheduler) dispatch(e *execution) {
+	decision, replaced := s.registry.admit(e)
+	for _, r := range replaced {
+		s.abort(r, model.RunStatusCancelled, "cancelled: replaced by a newer run")
+	}
+	switch decision {
+	case admitSkip:
+		s.log.Infof("⏭️ skip task %s (run %s): previous run is still running", e.task.GetID(), e.first.RunID)
+		s.abort(e, model.RunStatusSkipped, SkippedDueToOverlapMsg)
+	case admitQueue:
+		context.AfterFunc(e.ctx, func() {
+			if s.registry.dequeue(e) {
+				s.abort(e, model.RunStatusCancelled, "cancelled while waiting for previous run")
+			}
+		})
+	default:
+		s.submitAttempt(e, e.first)
+	}
+}
+
+// abort 结束一次没有开始执行的执行并记录日志
+func (s *CronScheduler) abort(e *execution, status, msg string) {
+	if status == model.RunStatusCancelled && errors.Is(context.Cause(e.ctx), ErrSchedulerStopped) {
+		status = model.RunStatusInterrupted
+	}
+	s.taskLog.record(e.task, abortedResult(e.first, status, msg))
+	s.registry.removePending(e.first.RunID)
+	e.first.closeOutput()
+	s.finish(e, status)
+}
+
+// submitAttempt 提交一次尝试到协程池，协程池已满时 Submit 会阻塞，放到单独的 goroutine 中提交，期间该尝试处于 pending 状态
+func (s *CronScheduler) submitAttempt(e *execution, rs *RunState) {
+	go func() {
+		if err := s.pool.Submit(func() { s.runAttempt(e, rs) }); err != nil {
+			s.log.Errorf("submit task to pool failed: %s,task:%v", err, e.task)
+			s.registry.removePending(rs.RunID)
+			rs.closeOutput()
+			s.finish(e, model.RunStatusCancelled)
+		}
+	}()
+}
+
+// runAttempt 在协程池中执行一次尝试，失败时按重试策略安排下一次尝试，否则结束本次执行
+func (s *CronScheduler) runAttempt(e *execution, rs *RunState) {
+	// 尝试结束并写入最终日志后才注销，注销后即可从任务日志查到本次尝试
+	unregister := s.registry.start(e.task, rs, e.cancel)
+	result := s.executor(WithRunState(e.ctx, rs), e.task)
+	unregister()
+	if s.retryLater(e, rs, result) {
+		return
+	}
+	s.finish(e, result.Status)
+}
+
+// retryLater 需要重试时登记下一次尝试为 pending 并在等待时间后提交，等待期间不占用协程池
+func (s *CronScheduler) retryLater(e *execution, rs *RunState, result TaskResult) bool {
+	policy := e.task.GetRetryPolicy()
+	if policy == nil || rs.Attempt >= policy.MaxAttempts || !policy.ShouldRetry(result) || e.ctx.Err() != nil {
+		return false
+	}
+
+	next := NewRunState(s.runIDGenerator.Generate(RunIDPrefix), e.task.GetID(), e.trigger)
+	next.ParentRunID = e.first.RunID
+	next.Attempt = rs.Attempt + 1
+	next.WorkflowRunID = e.workflowRunID
+	delay := policy.Delay(rs.Attempt)
+	s.log.Infof("🔁 retry task %s (run %s) in %v as run %s, attempt %d/%d, last status: %s",
+		e.task.GetID(), e.first.RunID, delay, next.RunID, next.Attempt, policy.MaxAttempts, result.Status)
+
+	s.registry.addPending(e.task, next, e.cancel)
+	timer := time.AfterFunc(delay, func() { s.submitAttempt(e, next) })
+	// 等待期间被取消或调度器停止时不再重试，本次执行以上一次尝试的结果结束
+	context.AfterFunc(e.ctx, func() {
+		if timer.Stop() {
+			s.registry.removePending(next.RunID)
+			next.closeOutput()
+			s.finish(e, result.Status)
+		}
+	})
+	return true
+}
+
+// finish 结束一次执行，开始同一任务排队中的下一个执行并推进所属的工作流
+func (s *CronScheduler) finish(e *execution, status string) {
+	e.cancel()
+	if next := s.registry.release(e); next != nil {
+		if next.ctx.Err() != nil {
+			s.abort(next, model.RunStatusCancelled, "cancelled while waiting for previous run")
+		} else {
+			s.submitAttempt(next, next.first)
+		}
+	}
+	if e.workflowRunID != "" {
+		s.advanceWorkflow(e.workflowRunID, e.task.GetOwnerName(), e.task.GetID(), status)
+	}
+	// 在 runs.Done 之前提交后续执行，停止时等待 runs 的过程中不会出现计数归零后又增加
+	if e.then != nil {
+		e.then()
+	}
+	s.runCount.Add(-1)
+	s.runs.Done()
+}
@@ -2,14 +2,24 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
 )
 
 type Executor func(ctx context.Context, t Task) TaskResult
 
 func BaseExecutor(ctx context.Context, t Task) TaskResult {
+	ctx, rs := ensureRunState(ctx, t)
+
+	limits := outputLimitsFromContext(ctx)
+	if limits.dir != "" && rs.RunID != "" {
+		rs.spool = newOutputSpool(limits.dir, rs.RunID, limits.limit)
+	}
+
 	start := time.Now()
 
 	var (
@@ -25,19 +35,57 @@ func BaseExecutor(ctx context.Context, t Task) TaskResult {
 		t.f.Run(ctx)
 	}()
 
-	errOutput := readChannel(t.f.ErrOutput())
+	errOutput := rs.MaskSecrets(readChannel(t.f.ErrOutput()))
 
 	if panicMsg != "" {
-		panicMsg = "Panic occurred: " + panicMsg
+		panicMsg = "Panic occurred: " + rs.MaskSecrets(panicMsg)
 		errOutput = strings.Join([]string{panicMsg, errOutput}, ";")
 	}
 
+	output := rs.MaskSecrets(readChannel(t.f.Output()))
+
+	status := resolveRunStatus(ctx, rs, panicMsg != "", errOutput)
+	exitCode := rs.ExitCode()
+	if status == model.RunStatusSucceeded && exitCode < 0 {
+		exitCode = 0
+	}
+
 	return TaskResult{
-		StartTime: start,
-		EndTime:   time.Now(),
-		Output:    readChannel(t.f.Output()),
-		ErrOutput: errOutput,
+		RunID:         rs.RunID,
+		ParentRunID:   rs.ParentRunID,
+		Attempt:       rs.Attempt,
+		WorkflowRunID: rs.WorkflowRunID,
+		Trigger:       rs.Trigger,
+		Status:        status,
+		ExitCode:      exitCode,
+		StartTime:     start,
+		EndTime:       time.Now(),
+		Output:        truncateOutput(output, limits.limit),
+		ErrOutput:     truncateOutput(errOutput, limits.limit),
+		OutputFile:    rs.finishSpool(OutputStreamStdout, output),
+		ErrOutputFile: rs.finishSpool(OutputStreamStderr, errOutput),
+		Usage:         rs.Usage(),
+	}
+}
+
+// resolveRunStatus 优先使用 Job 上报的状态，否则根据 panic、context 和错误输出推断
+func resolveRunStatus(ctx context.Context, rs *RunState, panicked bool, errOutput string) string {
+	if panicked {
+		return model.RunStatusPanicked
+	}
+	if status := rs.Status(); status != "" {
+		return status
+	}
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return model.RunStatusTimedOut
+		}
+		return model.RunStatusCancelled
+	}
+	if errOutput != "" {
+		return model.RunStatusFailed
 	}
+	return model.RunStatusSucceeded
 }
 
 func readChannel(ch <-chan string) string {
@@ -0,0 +1,436 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+const (
+	HTTPJobType = "http"
+
+	// httpOutputBodyLimit 写入任务输出的响应体最大字节数
+	httpOutputBodyLimit = 4 << 10
+	// httpReadBodyLimit 读取用于断言的响应体最大字节数
+	httpReadBodyLimit = 1 << 20
+)
+
+// 响应体断言方式
+const (
+	AssertionContains = "contains"  // 响应体包含子串
+	AssertionRegex    = "regex"     // 响应体匹配正则表达式
+	AssertionJSONPath = "json_path" // 响应体(JSON)中指定路径的值
+)
+
+// HTTPAssertion 对响应体的断言
+type HTTPAssertion struct {
+	Type     string `json:"type"`           // 断言方式 contains/regex/json_path
+	Path     string `json:"path,omitempty"` // json_path 断言的字段路径，如 data.items[0].id
+	Expected string `json:"expected"`       // 子串、正则表达式或期望值，json_path 断言为空时只要求字段存在
+}
+
+func (a HTTPAssertion) validate() error {
+	switch a.Type {
+	case AssertionContains:
+		return nil
+	case AssertionRegex:
+		if _, err := regexp.Compile(a.Expected); err != nil {
+			return fmt.Errorf("invalid regex assertion %q: %w", a.Expected, err)
+		}
+		return nil
+	case AssertionJSONPath:
+		if _, err := parseJSONPath(a.Path); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("assertion type %q unknown", a.Type)
+	}
+}
+
+// check 校验响应体，不满足时返回原因
+func (a HTTPAssertion) check(body []byte) error {
+	switch a.Type {
+	case AssertionContains:
+		if !strings.Contains(string(body), a.Expected) {
+			return fmt.Errorf("response body does not contain %q", a.Expected)
+		}
+	case AssertionRegex:
+		re, err := regexp.Compile(a.Expected)
+		if err != nil {
+			return err
+		}
+		if !re.Match(body) {
+			return fmt.Errorf("response body does not match %q", a.Expected)
+		}
+	case AssertionJSONPath:
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("response body is not valid json: %w", err)
+		}
+		value, err := lookupJSONPath(doc, a.Path)
+		if err != nil {
+			return err
+		}
+		if a.Expected != "" && jsonValueString(value) != a.Expected {
+			return fmt.Errorf("json path %s is %s, expected %s", a.Path, jsonValueString(value), a.Expected)
+		}
+	default:
+		return fmt.Errorf("assertion type %q unknown", a.Type)
+	}
+	return nil
+}
+
+// parseJSONPath 将 data.items[0].id 形式的路径拆分为字段名和数组下标，允许以 $. 开头
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, fmt.Errorf("json path cannot be empty")
+	}
+
+	var keys []string
+	for _, part := range strings.Split(path, ".") {
+		name, rest, hasIndex := strings.Cut(part, "[")
+		if name == "" && !hasIndex {
+			return nil, fmt.Errorf("invalid json path %q", path)
+		}
+		if name != "" {
+			keys = append(keys, name)
+		}
+		for hasIndex {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid json path %q", path)
+			}
+			if _, err := strconv.Atoi(index); err != nil {
+				return nil, fmt.Errorf("invalid array index %q in json path %q", index, path)
+			}
+			keys = append(keys, "["+index+"]")
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid json path %q", path)
+			}
+			rest, hasIndex = strings.CutPrefix(after, "[")
+		}
+	}
+	return keys, nil
+}
+
+func lookupJSONPath(doc any, path string) (any, error) {
+	keys, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := doc
+	for _, key := range keys {
+		if strings.HasPrefix(key, "[") {
+			index, _ := strconv.Atoi(strings.Trim(key, "[]"))
+			arr, ok := value.([]any)
+			if !ok || index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("json path %s not found", path)
+			}
+			value = arr[index]
+			continue
+		}
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("json path %s not found", path)
+		}
+		if value, ok = obj[key]; !ok {
+			return nil, fmt.Errorf("json path %s not found", path)
+		}
+	}
+	return value, nil
+}
+
+// jsonValueString 字符串直接返回，其他值返回其 JSON 表示
+func jsonValueString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	res, _ := json.Marshal(value)
+	return string(res)
+}
+
+// HTTPJob 发送一次 HTTP 请求，并按状态码和响应体断言判断是否成功
+type HTTPJob struct {
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers"`
+	Body           string            `json:"body"`
+	Timeout        time.Duration     `json:"timeout"`
+	ExpectedStatus []int             `json:"expected_status"` // 期望的状态码，为空时要求 2xx
+	Assertions     []HTTPAssertion   `json:"assertions"`      // 响应体断言，全部满足才算成功
+	output         chan string       // 标准输出
+	errOutput      chan string       // 错误输出
+}
+
+func NewHTTPJob(method, url string, headers map[string]string, body string, timeOut time.Duration, expectedStatus []int, assertions ...HTTPAssertion) *HTTPJob {
+	return &HTTPJob{
+		Method:         strings.ToUpper(method),
+		URL:            url,
+		Headers:        headers,
+		Body:           body,
+		Timeout:        timeOut,
+		ExpectedStatus: expectedStatus,
+		Assertions:     assertions,
+		output:         make(chan string, 100),
+		errOutput:      make(chan string, 100),
+	}
+}
+
+func (h *HTTPJob) Type() string {
+	return HTTPJobType
+}
+
+const httpJobSchema = `{
+  "type": "object",
+  "required": ["method", "url", "timeout"],
+  "additionalProperties": false,
+  "properties": {
+    "method": {"enum": ["GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"], "description": "请求方法"},
+    "url": {"type": "string", "format": "uri", "description": "请求地址(http/https)"},
+    "headers": {"type": "object", "additionalProperties": {"type": "string"}, "description": "请求头"},
+    "body": {"type": "string", "description": "请求体"},
+    "timeout": {"type": "integer", "exclusiveMinimum": 0, "maximum": 600, "description": "超时时间(秒)"},
+    "expected_status": {"type": "array", "items": {"type": "integer", "minimum": 100, "maximum": 599}, "description": "期望的状态码，默认2xx"},
+    "assertions": {
+      "type": "array",
+      "maxItems": 10,
+      "description": "响应体断言，全部满足才算成功",
+      "items": {
+        "type": "object",
+        "required": ["type"],
+        "additionalProperties": false,
+        "properties": {
+          "type": {"enum": ["contains", "regex", "json_path"], "description": "断言方式"},
+          "path": {"type": "string", "description": "json_path 断言的字段路径，如 data.items[0].id，json_path 断言必填"},
+          "expected": {"type": "string", "description": "子串、正则表达式或期望值，contains 和 regex 断言必填，json_path 断言为空时只要求字段存在"}
+        }
+      }
+    }
+  }
+}`
+
+// httpJobSpec 创建HTTP任务时 job 字段的结构
+type httpJobSpec struct {
+	Method         string            `json:"method" binding:"required,oneof=GET POST PUT PATCH DELETE HEAD"`
+	URL            string            `json:"url" binding:"required,url"`
+	Headers        map[string]string `json:"headers"`
+	Body           string            `json:"body"`
+	Timeout        int               `json:"timeout" binding:"required,max=600,gt=0"`
+	ExpectedStatus []int             `json:"expected_status" binding:"omitempty,dive,min=100,max=599"`
+	Assertions     []struct {
+		Type     string `json:"type" binding:"required,oneof=contains regex json_path"`
+		Path     string `json:"path" binding:"required_if=Type json_path"`
+		Expected string `json:"expected" binding:"required_unless=Type json_path"`
+	} `json:"assertions" binding:"omitempty,max=10,dive"`
+}
+
+func bindHTTPJob(_ JobBindContext, raw json.RawMessage) (Job, error) {
+	var spec httpJobSpec
+	if err := bindJobSpec(raw, &spec); err != nil {
+		return nil, err
+	}
+	assertions := make([]HTTPAssertion, 0, len(spec.Assertions))
+	for _, a := range spec.Assertions {
+		assertions = append(assertions, HTTPAssertion{Type: a.Type, Path: a.Path, Expected: a.Expected})
+	}
+	job := NewHTTPJob(spec.Method, spec.URL, spec.Headers, spec.Body, time.Duration(spec.Timeout)*time.Second, spec.ExpectedStatus, assertions...)
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Validate 校验请求地址和断言配置
+func (h *HTTPJob) Validate() error {
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url host cannot be empty")
+	}
+	for _, a := range h.Assertions {
+		if err := a.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *HTTPJob) Content() string {
+	if h == nil {
+		return ""
+	}
+
+	type result struct {
+		Method         string            `json:"method"`
+		URL            string            `json:"url"`
+		Headers        map[string]string `json:"headers,omitempty"`
+		Body           string            `json:"body,omitempty"`
+		TimeOut        string            `json:"timeout"`
+		ExpectedStatus []int             `json:"expected_status,omitempty"`
+		Assertions     []HTTPAssertion   `json:"assertions,omitempty"`
+	}
+
+	res := result{
+		Method:         h.Method,
+		URL:            h.URL,
+		Headers:        h.Headers,
+		Body:           h.Body,
+		TimeOut:        h.Timeout.String(),
+		ExpectedStatus: h.ExpectedStatus,
+		Assertions:     h.Assertions,
+	}
+
+	resStr, _ := json.Marshal(res)
+	return string(resStr)
+}
+
+// SecretRefs 返回请求地址、请求头和请求体中引用的密钥
+func (h *HTTPJob) SecretRefs() []string {
+	return secretRefs(append([]string{h.URL, h.Body}, envValues(h.Headers)...)...)
+}
+
+func (h *HTTPJob) Run(ctx context.Context) {
+	reqCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	rs, hasRunState := RunStateFromContext(ctx)
+	setStatus := func(status string) {
+		if hasRunState {
+			rs.SetStatus(status)
+		}
+	}
+
+	// 密钥引用只在发送请求时替换，Content 和 ToJson 中保留原始引用
+	expanded, err := expandAllSecrets(ctx, []string{h.URL, h.Body})
+	if err != nil {
+		setStatus(model.RunStatusFailed)
+		h.errOutput <- fmt.Sprintf("Request error: %v", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(reqCtx, h.Method, expanded[0], strings.NewReader(expanded[1]))
+	if err != nil {
+		setStatus(model.RunStatusFailed)
+		h.errOutput <- fmt.Sprintf("Request error: %v", err)
+		return
+	}
+	for k, v := range h.Headers {
+		value, err := expandSecrets(ctx, v)
+		if err != nil {
+			setStatus(model.RunStatusFailed)
+			h.errOutput <- fmt.Sprintf("Request error: %v", err)
+			return
+		}
+		req.Header.Set(k, value)
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		switch {
+		case errors.Is(reqCtx.Err(), context.DeadlineExceeded):
+			setStatus(model.RunStatusTimedOut)
+		case errors.Is(reqCtx.Err(), context.Canceled):
+			setStatus(model.RunStatusCancelled)
+		default:
+			setStatus(model.RunStatusFailed)
+		}
+		h.errOutput <- fmt.Sprintf("Request error: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpReadBodyLimit))
+	if err != nil {
+		setStatus(model.RunStatusFailed)
+		h.errOutput <- fmt.Sprintf("Read response body error: %v", err)
+		return
+	}
+
+	summary := fmt.Sprintf("%s %s -> %s (%s)", h.Method, h.URL, resp.Status, time.Since(start).Round(time.Millisecond))
+	if hasRunState {
+		rs.PublishOutput(OutputStreamStdout, summary)
+	}
+	output := summary + "\n" + truncateBody(body)
+
+	var failures []string
+	if !h.statusExpected(resp.StatusCode) {
+		failures = append(failures, fmt.Sprintf("unexpected status code %d", resp.StatusCode))
+	}
+	for _, a := range h.Assertions {
+		if err := a.check(body); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	h.output <- output
+	if len(failures) > 0 {
+		setStatus(model.RunStatusFailed)
+		h.errOutput <- "Assertion failed: " + strings.Join(failures, "; ")
+		return
+	}
+	setStatus(model.RunStatusSucceeded)
+}
+
+func (h *HTTPJob) statusExpected(code int) bool {
+	if len(h.ExpectedStatus) == 0 {
+		return code >= 200 && code < 300
+	}
+	return slices.Contains(h.ExpectedStatus, code)
+}
+
+// truncateBody 截断写入任务输出的响应体
+func truncateBody(body []byte) string {
+	if len(body) <= httpOutputBodyLimit {
+		return string(body)
+	}
+	return fmt.Sprintf("%s\n...(truncated, %d bytes in total)", body[:httpOutputBodyLimit], len(body))
+}
+
+func (h *HTTPJob) Output() <-chan string {
+	return h.output
+}
+
+func (h *HTTPJob) ErrOutput() <-chan string {
+	return h.errOutput
+}
+
+func (h *HTTPJob) ToJson() string {
+	res, _ := json.Marshal(h)
+	return string(res)
+}
+
+func (h *HTTPJob) UnmarshalFromJson(jsonStr string) error {
+	if h == nil {
+		return fmt.Errorf("cannot unmarshall from json: nil pointer")
+	}
+
+	type Alias HTTPJob // 防止递归调用
+	if err := json.Unmarshal([]byte(jsonStr), (*Alias)(h)); err != nil {
+		return err
+	}
+	h.output = make(chan string, 100)
+	h.errOutput = make(chan string, 100)
+	return nil
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPJob_Run(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"status":"ok","data":{"items":[{"id":42,"name":"db"}]}}`)
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Token"), body)
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+		case "/large":
+			fmt.Fprint(w, strings.Repeat("a", httpOutputBodyLimit*2))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		job      *HTTPJob
+		status   string
+		contains string
+	}{
+		{
+			name:     "default 2xx",
+			job:      NewHTTPJob(http.MethodGet, server.URL+"/health", nil, "", 5*time.Second, nil),
+			status:   model.RunStatusSucceeded,
+			contains: "200 OK",
+		},
+		{
+			name: "method headers and body",
+			job: NewHTTPJob("post", server.URL+"/echo", map[string]string{"X-Token": "secret"}, "payload", 5*time.Second, nil,
+				HTTPAssertion{Type: AssertionContains, Expected: "POST secret payload"}),
+			status: model.RunStatusSucceeded,
+		},
+		{
+			name:   "unexpected status",
+			job:    NewHTTPJob(http.MethodGet, server.URL+"/missing", nil, "", 5*time.Second, nil),
+			status: model.RunStatusFailed,
+		},
+		{
+			name:   "expected status",
+			job:    NewHTTPJob(http.MethodGet, server.URL+"/missing", nil, "", 5*time.Second, []int{http.StatusServiceUnavailable}),
+			status: model.RunStatusSucceeded,
+		},
+		{
+			name: "assertions pass",
+			job: NewHTTPJob(http.MethodGet, server.URL+"/health", nil, "", 5*time.Second, nil,
+				HTTPAssertion{Type: AssertionRegex, Expected: `"status":\s*"ok"`},
+				HTTPAssertion{Type: AssertionJSONPath, Path: "$.data.items[0].id", Expected: "42"},
+				HTTPAssertion{Type: AssertionJSONPath, Path: "data.items[0].name", Expected: "db"},
+				HTTPAssertion{Type: AssertionJSONPath, Path: "status"}),
+			status: model.RunStatusSucceeded,
+		},
+		{
+			name: "json path assertion fails",
+			job: NewHTTPJob(http.MethodGet, server.URL+"/health", nil, "", 5*time.Second, nil,
+				HTTPAssertion{Type: AssertionJSONPath, Path: "data.items[1].id"}),
+			status: model.RunStatusFailed,
+		},
+		{
+			name:   "timeout",
+			job:    NewHTTPJob(http.MethodGet, server.URL+"/slow", nil, "", 100*time.Millisecond, nil),
+			status: model.RunStatusTimedOut,
+		},
+		{
+			name:     "truncated body",
+			job:      NewHTTPJob(http.MethodGet, server.URL+"/large", nil, "", 5*time.Second, nil),
+			status:   model.RunStatusSucceeded,
+			contains: fmt.Sprintf("truncated, %d bytes in total", httpOutputBodyLimit*2),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.job.Validate())
+
+			rs := NewRunState("run_test", "task_test", model.TriggerManual)
+			tt.job.Run(WithRunState(context.Background(), rs))
+
+			assert.Equal(t, tt.status, rs.Status())
+			output := readChannel(tt.job.Output())
+			errOutput := readChannel(tt.job.ErrOutput())
+			if tt.status == model.RunStatusSucceeded {
+				assert.Empty(t, errOutput)
+			} else {
+				assert.NotEmpty(t, errOutput)
+			}
+			assert.Contains(t, output, tt.contains)
+			assert.LessOrEqual(t, len(output), httpOutputBodyLimit+200)
+		})
+	}
+}
+
+func TestHTTPJob_Validate(t *testing.T) {
+	assert.Error(t, NewHTTPJob(http.MethodGet, "ftp://example.com", nil, "", time.Second, nil).Validate())
+	assert.Error(t, NewHTTPJob(http.MethodGet, "http://", nil, "", time.Second, nil).Validate())
+	assert.Error(t, NewHTTPJob(http.MethodGet, "http://example.com", nil, "", time.Second, nil,
+		HTTPAssertion{Type: AssertionRegex, Expected: "("}).Validate())
+	assert.Error(t, NewHTTPJob(http.MethodGet, "http://example.com", nil, "", time.Second, nil,
+		HTTPAssertion{Type: AssertionJSONPath, Path: "items[x]"}).Validate())
+	assert.Error(t, NewHTTPJob(http.MethodGet, "http://example.com", nil, "", time.Second, nil,
+		HTTPAssertion{Type: "xpath"}).Validate())
+}
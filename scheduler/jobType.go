@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// JobBindContext 根据请求参数构造 Job 时可用的上下文
+type JobBindContext struct {
+	WorkDir  string // 任务工作目录的根目录
+	UserName string // 任务拥有者
+	UseShell bool   // 用户是否允许通过 Shell 执行命令
+}
+
+// JobBinder 将创建任务请求中的 job 字段解析、校验并构造为 Job
+type JobBinder func(bc JobBindContext, raw json.RawMessage) (Job, error)
+
+// JobType 一种任务类型的注册信息
+type JobType struct {
+	Name   string     // 任务类型，对应 Job.Type() 和 task_infos.job_type
+	New    func() Job // 构造空的 Job，用于从数据库中反序列化
+	Schema string     // 描述创建任务时 job 字段结构的 JSON Schema
+	Bind   JobBinder  // 请求参数绑定
+}
+
+var (
+	jobTypesMu sync.RWMutex
+	jobTypes   = make(map[string]JobType)
+)
+
+// RegisterJobType 注册任务类型，重复注册会覆盖之前的定义
+func RegisterJobType(jt JobType) {
+	if jt.Name == "" || jt.New == nil || jt.Bind == nil {
+		panic("scheduler: job type must have name, factory and binder")
+	}
+	if jt.Schema == "" {
+		jt.Schema = "{}"
+	}
+	jobTypesMu.Lock()
+	defer jobTypesMu.Unlock()
+	jobTypes[jt.Name] = jt
+}
+
+// LookupJobType 查询已注册的任务类型
+func LookupJobType(name string) (JobType, bool) {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	jt, ok := jobTypes[name]
+	return jt, ok
+}
+
+// ListJobTypes 按名称顺序列出已注册的任务类型
+func ListJobTypes() []JobType {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	res := make([]JobType, 0, len(jobTypes))
+	for _, jt := range jobTypes {
+		res = append(res, jt)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func GetJob(jobType string) (Job, error) {
+	jt, ok := LookupJobType(jobType)
+	if !ok {
+		return nil, fmt.Errorf("job type unknown")
+	}
+	return jt.New(), nil
+}
+
+// bindJobSpec 严格解析 job 字段并按 binding 标签校验，与控制器中请求参数的校验规则一致
+func bindJobSpec(raw json.RawMessage, spec any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return fmt.Errorf("invalid job: %w", err)
+	}
+	if err := binding.Validator.ValidateStruct(spec); err != nil {
+		return fmt.Errorf("invalid job: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	RegisterJobType(JobType{
+		Name:   ShellJobType,
+		New:    func() Job { return new(ShellJob) },
+		Schema: shellJobSchema,
+		Bind:   bindShellJob,
+	})
+	RegisterJobType(JobType{
+		Name:   PipelineJobType,
+		New:    func() Job { return new(PipelineJob) },
+		Schema: pipelineJobSchema,
+		Bind:   bindPipelineJob,
+	})
+	RegisterJobType(JobType{
+		Name:   HTTPJobType,
+		New:    func() Job { return new(HTTPJob) },
+		Schema: httpJobSchema,
+		Bind:   bindHTTPJob,
+	})
+}
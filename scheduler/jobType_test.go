@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobTypes_Builtin(t *testing.T) {
+	names := make([]string, 0)
+	for _, jt := range ListJobTypes() {
+		names = append(names, jt.Name)
+		assert.True(t, json.Valid([]byte(jt.Schema)), "schema of %s should be valid json", jt.Name)
+
+		job, err := GetJob(jt.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, jt.Name, job.Type())
+	}
+	assert.Equal(t, []string{HTTPJobType, PipelineJobType, ShellJobType}, names)
+
+	_, err := GetJob("unknown")
+	assert.Error(t, err)
+}
+
+func TestJobTypes_Bind(t *testing.T) {
+	bc := JobBindContext{WorkDir: "/data", UserName: "alice"}
+	shell, _ := LookupJobType(ShellJobType)
+
+	job, err := shell.Bind(bc, json.RawMessage(`{"command":"./backup.sh","args":["--full"],"timeout":60}`))
+	assert.NoError(t, err)
+	shellJob := job.(*ShellJob)
+	assert.Equal(t, "./backup.sh", shellJob.Command)
+	assert.Equal(t, time.Minute, shellJob.Timeout)
+	assert.Equal(t, "alice", shellJob.userName)
+
+	_, err = shell.Bind(bc, json.RawMessage(`{"command":"ls","timeout":60,"use_shell":true}`))
+	assert.ErrorContains(t, err, "not allowed to use shell")
+
+	_, err = shell.Bind(bc, json.RawMessage(`{"command":"ls","timeout":0}`))
+	assert.Error(t, err, "timeout is required")
+
+	_, err = shell.Bind(bc, json.RawMessage(`{"command":"ls","timeout":60,"cmd":"typo"}`))
+	assert.Error(t, err, "unknown fields are rejected")
+
+	env := make(map[string]string)
+	for i := 0; i < 51; i++ {
+		env[fmt.Sprintf("K%d", i)] = "v"
+	}
+	raw, _ := json.Marshal(map[string]any{"command": "ls", "timeout": 60, "env": env})
+	_, err = shell.Bind(bc, raw)
+	assert.Error(t, err, "at most 50 env entries")
+
+	pipeline, _ := LookupJobType(PipelineJobType)
+	job, err = pipeline.Bind(bc, json.RawMessage(`{"steps":[{"name":"dump","command":"pg_dump","timeout":600,"continue_on_error":true}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []PipelineStep{{Name: "dump", Command: "pg_dump", Timeout: 10 * time.Minute, ContinueOnError: true}}, job.(*PipelineJob).Steps)
+
+	_, err = pipeline.Bind(bc, json.RawMessage(`{"steps":[]}`))
+	assert.Error(t, err)
+
+	raw, _ = json.Marshal(map[string]any{"env": env, "steps": []map[string]any{{"command": "ls", "timeout": 60}}})
+	_, err = pipeline.Bind(bc, raw)
+	assert.Error(t, err, "at most 50 env entries")
+
+	httpType, _ := LookupJobType(HTTPJobType)
+	_, err = httpType.Bind(bc, json.RawMessage(`{"method":"GET","url":"http://example.com/health","timeout":10,"assertions":[{"type":"regex","expected":"("}]}`))
+	assert.Error(t, err, "invalid regex is rejected")
+
+	_, err = httpType.Bind(bc, json.RawMessage(`{"method":"GET","url":"http://example.com/health","timeout":10,"assertions":[{"type":"contains"}]}`))
+	assert.Error(t, err, "contains assertion requires expected")
+
+	_, err = httpType.Bind(bc, json.RawMessage(`{"method":"GET","url":"http://example.com/health","timeout":10,"assertions":[{"type":"json_path","expected":"ok"}]}`))
+	assert.Error(t, err, "json_path assertion requires path")
+
+	job, err = httpType.Bind(bc, json.RawMessage(`{"method":"GET","url":"http://example.com/health","timeout":10,"assertions":[{"type":"json_path","path":"data.status"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []HTTPAssertion{{Type: AssertionJSONPath, Path: "data.status"}}, job.(*HTTPJob).Assertions)
+}
@@ -0,0 +1,169 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/dao"
+	"github.com/chencheng8888/GoDo/dao/model"
+	"go.uber.org/zap"
+)
+
+// ErrLimitExceeded 进程因超出资源限制被终止
+var ErrLimitExceeded = errors.New("resource limit exceeded")
+
+// LimitedJob 可以单独设置资源限制的 Job
+type LimitedJob interface {
+	GetLimits() *model.ResourceLimits
+}
+
+// resourceLimitsSchema 创建任务时 limits 字段的 JSON Schema
+const resourceLimitsSchema = `{
+      "type": "object",
+      "additionalProperties": false,
+      "description": "资源限制，不能超过用户的默认限制，0表示使用用户默认值",
+      "properties": {
+        "cpu_seconds": {"type": "integer", "minimum": 0, "description": "CPU 时间(秒)"},
+        "address_space_mb": {"type": "integer", "minimum": 0, "description": "虚拟地址空间(MB)，启用 cgroup 时同时作为内存上限"},
+        "open_files": {"type": "integer", "minimum": 0, "description": "打开文件数"},
+        "processes": {"type": "integer", "minimum": 0, "description": "进程数"},
+        "file_size_mb": {"type": "integer", "minimum": 0, "description": "可写入的单个文件大小(MB)"}
+      }
+    }`
+
+// effectiveLimits 任务设置的限制覆盖用户默认值，但不能超过用户默认值
+func effectiveLimits(user model.ResourceLimits, task *model.ResourceLimits) model.ResourceLimits {
+	if task == nil {
+		return user
+	}
+	pick := func(u, t uint64) uint64 {
+		if t > 0 && (u == 0 || t < u) {
+			return t
+		}
+		return u
+	}
+	return model.ResourceLimits{
+		CPUSeconds:     pick(user.CPUSeconds, task.CPUSeconds),
+		AddressSpaceMB: pick(user.AddressSpaceMB, task.AddressSpaceMB),
+		OpenFiles:      pick(user.OpenFiles, task.OpenFiles),
+		Processes:      pick(user.Processes, task.Processes),
+		FileSizeMB:     pick(user.FileSizeMB, task.FileSizeMB),
+	}
+}
+
+// ValidateLimits 校验任务的资源限制没有超过用户的默认限制
+func ValidateLimits(task *model.ResourceLimits, user model.ResourceLimits) error {
+	if task == nil {
+		return nil
+	}
+	check := func(name string, u, t uint64) error {
+		if u > 0 && t > u {
+			return fmt.Errorf("%s limit %d exceeds the user limit %d", name, t, u)
+		}
+		return nil
+	}
+	return errors.Join(
+		check("cpu_seconds", user.CPUSeconds, task.CPUSeconds),
+		check("address_space_mb", user.AddressSpaceMB, task.AddressSpaceMB),
+		check("open_files", user.OpenFiles, task.OpenFiles),
+		check("processes", user.Processes, task.Processes),
+		check("file_size_mb", user.FileSizeMB, task.FileSizeMB),
+	)
+}
+
+// runLimits 本次执行生效的资源限制
+type runLimits struct {
+	model.ResourceLimits
+	cgroupRoot string // 为空时不使用 cgroup
+}
+
+type runLimitsKey struct{}
+
+func withRunLimits(ctx context.Context, limits runLimits) context.Context {
+	return context.WithValue(ctx, runLimitsKey{}, limits)
+}
+
+func runLimitsFromContext(ctx context.Context) (runLimits, bool) {
+	limits, ok := ctx.Value(runLimitsKey{}).(runLimits)
+	return limits, ok
+}
+
+// userLimitsTTL 用户默认限制的缓存时间，修改数据库中的限制后最多经过该时间生效
+const userLimitsTTL = time.Minute
+
+type cachedLimits struct {
+	limits   model.ResourceLimits
+	loadedAt time.Time
+}
+
+// LimitMiddleware 合并用户默认限制和任务限制写入 context，由启动进程的 Job 在进程启动时应用
+type LimitMiddleware struct {
+	log        *zap.SugaredLogger
+	userDao    *dao.UserDao
+	cgroupRoot string
+
+	mu     sync.Mutex
+	limits map[string]cachedLimits // 用户名 -> 用户默认限制
+}
+
+func NewLimitMiddleware(conf *config.ScheduleConfig, userDao *dao.UserDao, log *zap.SugaredLogger) *LimitMiddleware {
+	m := &LimitMiddleware{log: log, userDao: userDao, limits: make(map[string]cachedLimits)}
+	if conf.CgroupRoot != "" {
+		if err := setupCgroupRoot(conf.CgroupRoot); err != nil {
+			log.Warnf("cgroup %s is unavailable, resource limits fall back to rlimit: %v", conf.CgroupRoot, err)
+		} else {
+			m.cgroupRoot = conf.CgroupRoot
+		}
+	}
+	return m
+}
+
+func (lm *LimitMiddleware) Handler(next Executor) Executor {
+	return func(ctx context.Context, t Task) TaskResult {
+		job, ok := t.f.(LimitedJob)
+		if !ok {
+			return next(ctx, t)
+		}
+
+		ctx, rs := ensureRunState(ctx, t)
+		userLimits, err := lm.userLimits(t.ownerName)
+		if err != nil {
+			lm.log.Errorf("load resource limits of user %s for task %s failed: %v", t.ownerName, t.id, err)
+			return abortedResult(rs, model.RunStatusFailed, fmt.Sprintf("load resource limits failed: %v", err))
+		}
+
+		limits := effectiveLimits(userLimits, job.GetLimits())
+		if limits.IsZero() {
+			return next(ctx, t)
+		}
+		return next(withRunLimits(ctx, runLimits{ResourceLimits: limits, cgroupRoot: lm.cgroupRoot}), t)
+	}
+}
+
+// userLimits 返回用户的默认限制，缓存过期后重新查询，查询失败时沿用上一次查到的值，避免数据库不可用时任务无法执行
+func (lm *LimitMiddleware) userLimits(userName string) (model.ResourceLimits, error) {
+	lm.mu.Lock()
+	cached, ok := lm.limits[userName]
+	lm.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < userLimitsTTL {
+		return cached.limits, nil
+	}
+
+	user, err := lm.userDao.GetUser(userName)
+	if err != nil {
+		if ok {
+			lm.log.Warnf("reload resource limits of user %s failed, use the last known limits: %v", userName, err)
+			return cached.limits, nil
+		}
+		return model.ResourceLimits{}, err
+	}
+
+	lm.mu.Lock()
+	lm.limits[userName] = cachedLimits{limits: user.Limits, loadedAt: time.Now()}
+	lm.mu.Unlock()
+	return user.Limits, nil
+}
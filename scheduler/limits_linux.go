@@ -0,0 +1,229 @@
+//go:build linux
+
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"golang.org/x/sys/unix"
+)
+
+// setupCgroupRoot 确认目录位于 cgroup v2 文件系统中，并为子 cgroup 启用内存和进程数控制器
+func setupCgroupRoot(root string) error {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return err
+	}
+	var st unix.Statfs_t
+	if err := unix.Statfs(root, &st); err != nil {
+		return err
+	}
+	if st.Type != unix.CGROUP2_SUPER_MAGIC {
+		return fmt.Errorf("%s is not a cgroup v2 directory", root)
+	}
+	return os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), []byte("+memory +pids"), 0o644)
+}
+
+// processLimiter 对单个进程应用资源限制，并在进程结束后判断是否因超出限制而终止
+type processLimiter struct {
+	limits runLimits
+
+	cgroupDir string
+	cgroupFD  *os.File
+}
+
+// newProcessLimiter 在进程启动前调用，设置 rlimit 并创建 cgroup，context 中没有资源限制时返回 nil
+func newProcessLimiter(ctx context.Context, cmd *exec.Cmd) (*processLimiter, error) {
+	limits, ok := runLimitsFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	if err := limitCommand(cmd, limits.ResourceLimits); err != nil {
+		return nil, err
+	}
+	l := &processLimiter{limits: limits}
+	if limits.cgroupRoot == "" || (limits.AddressSpaceMB == 0 && limits.Processes == 0) {
+		return l, nil
+	}
+
+	dir, err := os.MkdirTemp(limits.cgroupRoot, "run-")
+	if err != nil {
+		return nil, fmt.Errorf("create cgroup failed: %w", err)
+	}
+	l.cgroupDir = dir
+	if limits.AddressSpaceMB > 0 {
+		if err := l.writeCgroup("memory.max", strconv.FormatUint(limits.AddressSpaceMB<<20, 10)); err != nil {
+			l.cleanup()
+			return nil, err
+		}
+		// 未开启 swap 时该文件不存在
+		_ = l.writeCgroup("memory.swap.max", "0")
+	}
+	if limits.Processes > 0 {
+		if err := l.writeCgroup("pids.max", strconv.FormatUint(limits.Processes, 10)); err != nil {
+			l.cleanup()
+			return nil, err
+		}
+	}
+
+	fd, err := os.Open(dir)
+	if err != nil {
+		l.cleanup()
+		return nil, err
+	}
+	l.cgroupFD = fd
+	// 通过 clone3 直接在目标 cgroup 中创建进程，避免启动后再迁移造成的间隙
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(fd.Fd())
+	return l, nil
+}
+
+func (l *processLimiter) writeCgroup(file, value string) error {
+	if err := os.WriteFile(filepath.Join(l.cgroupDir, file), []byte(value), 0o644); err != nil {
+		return fmt.Errorf("set cgroup %s failed: %w", file, err)
+	}
+	return nil
+}
+
+// limitCommand 通过 bash 的 ulimit 在目标程序 exec 之前设置 rlimit，目标程序及其子进程都会继承这些限制
+func limitCommand(cmd *exec.Cmd, limits model.ResourceLimits) error {
+	if cmd.Err != nil {
+		// 命令查找失败，由 Start 返回错误
+		return nil
+	}
+
+	var script []string
+	// value 和 slack 以 MB、秒或个数表示，scale 换算为 rlimit 的单位，unit 为 ulimit 参数的单位
+	add := func(flag string, resource int, value, slack, scale, unit uint64) error {
+		if value == 0 {
+			return nil
+		}
+		var cur unix.Rlimit
+		if err := unix.Getrlimit(resource, &cur); err != nil {
+			return err
+		}
+		// 子进程继承服务的硬限制，没有 CAP_SYS_RESOURCE 时无法提高
+		soft := min(value*scale, cur.Max) / unit
+		hard := min((value+slack)*scale, cur.Max) / unit
+		// 先降低软限制，硬限制不能低于当前的软限制
+		script = append(script, fmt.Sprintf("ulimit -S %s %d && ulimit -H %s %d", flag, soft, flag, hard))
+		return nil
+	}
+	// CPU 时间达到软限制时发送 SIGXCPU，再多1秒达到硬限制时发送 SIGKILL
+	// RLIMIT_NPROC 按用户统计进程数，以服务相同用户运行时会包含服务自身的进程
+	err := errors.Join(
+		add("-t", unix.RLIMIT_CPU, limits.CPUSeconds, 1, 1, 1),
+		add("-v", unix.RLIMIT_AS, limits.AddressSpaceMB, 0, 1<<20, 1<<10),
+		add("-n", unix.RLIMIT_NOFILE, limits.OpenFiles, 0, 1, 1),
+		add("-u", unix.RLIMIT_NPROC, limits.Processes, 0, 1, 1),
+		add("-f", unix.RLIMIT_FSIZE, limits.FileSizeMB, 0, 1<<20, 1<<10),
+	)
+	if err != nil {
+		return fmt.Errorf("get rlimit failed: %w", err)
+	}
+	if len(script) == 0 {
+		return nil
+	}
+
+	script = append(script, `exec "$@"`)
+	cmd.Args = append([]string{"/bin/bash", "-c", strings.Join(script, " && "), "godo", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = "/bin/bash"
+	return nil
+}
+
+// check 进程异常退出时判断是否因超出资源限制，是则返回包装了 ErrLimitExceeded 的错误
+func (l *processLimiter) check(state *os.ProcessState, err error) error {
+	if l == nil || err == nil || state == nil {
+		return err
+	}
+
+	var exceeded []string
+	if sig, ok := terminatedBy(state); ok {
+		switch sig {
+		case syscall.SIGXCPU:
+			exceeded = append(exceeded, "cpu time")
+		case syscall.SIGXFSZ:
+			exceeded = append(exceeded, "file size")
+		case syscall.SIGKILL:
+			// 达到 CPU 硬限制时由内核发送 SIGKILL
+			if l.limits.CPUSeconds > 0 && state.UserTime()+state.SystemTime() >= time.Duration(l.limits.CPUSeconds)*time.Second {
+				exceeded = append(exceeded, "cpu time")
+			}
+		}
+	}
+	if l.cgroupDir != "" {
+		if readCgroupEvent(filepath.Join(l.cgroupDir, "memory.events"), "oom_kill") > 0 {
+			exceeded = append(exceeded, "memory")
+		}
+		if readCgroupEvent(filepath.Join(l.cgroupDir, "pids.events"), "max") > 0 {
+			exceeded = append(exceeded, "processes")
+		}
+	}
+	if len(exceeded) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrLimitExceeded, strings.Join(exceeded, ", "))
+}
+
+// terminatedBy 返回终止进程的信号，通过 Shell 执行时 Shell 以 128+信号值 作为退出码
+func terminatedBy(state *os.ProcessState) (syscall.Signal, bool) {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return 0, false
+	}
+	if ws.Signaled() {
+		return ws.Signal(), true
+	}
+	if code := ws.ExitStatus(); code > 128 && code < 128+65 {
+		return syscall.Signal(code - 128), true
+	}
+	return 0, false
+}
+
+// readCgroupEvent 读取 cgroup 事件文件中指定事件的计数
+func readCgroupEvent(file, event string) int {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == event {
+			n, _ := strconv.Atoi(fields[1])
+			return n
+		}
+	}
+	return 0
+}
+
+// cleanup 结束 cgroup 中残留的进程并删除 cgroup
+func (l *processLimiter) cleanup() {
+	if l == nil || l.cgroupDir == "" {
+		return
+	}
+	if l.cgroupFD != nil {
+		_ = l.cgroupFD.Close()
+	}
+	_ = l.writeCgroup("cgroup.kill", "1")
+	for i := 0; i < 10; i++ {
+		if err := os.Remove(l.cgroupDir); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveLimits(t *testing.T) {
+	user := model.ResourceLimits{CPUSeconds: 60, OpenFiles: 256}
+	task := &model.ResourceLimits{CPUSeconds: 10, OpenFiles: 1024, Processes: 32}
+
+	assert.Equal(t, user, effectiveLimits(user, nil))
+	assert.Equal(t, model.ResourceLimits{CPUSeconds: 10, OpenFiles: 256, Processes: 32}, effectiveLimits(user, task))
+
+	assert.Error(t, ValidateLimits(task, user))
+	assert.NoError(t, ValidateLimits(&model.ResourceLimits{CPUSeconds: 10}, user))
+}
+
+func TestShellJob_ResourceLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		useShell bool
+		command  string
+		args     []string
+		limits   model.ResourceLimits
+		status   string
+	}{
+		{
+			name:     "cpu time",
+			useShell: true,
+			command:  "while :; do :; done",
+			limits:   model.ResourceLimits{CPUSeconds: 1},
+			status:   model.RunStatusLimitExceeded,
+		},
+		{
+			name:     "file size",
+			useShell: true,
+			command:  "head -c 2097152 /dev/zero > out.bin",
+			limits:   model.ResourceLimits{FileSizeMB: 1},
+			status:   model.RunStatusLimitExceeded,
+		},
+		{
+			name:    "file size without shell",
+			command: "dd",
+			args:    []string{"if=/dev/zero", "of=out.bin", "bs=1M", "count=2"},
+			limits:  model.ResourceLimits{FileSizeMB: 1},
+			status:  model.RunStatusLimitExceeded,
+		},
+		{
+			name:     "within limits",
+			useShell: true,
+			command:  "head -c 1024 /dev/zero > out.bin",
+			limits:   model.ResourceLimits{CPUSeconds: 5, FileSizeMB: 1, OpenFiles: 64},
+			status:   model.RunStatusSucceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewShellJob(tt.useShell, 10*time.Second, t.TempDir(), "tester", tt.command, tt.args...)
+			rs := NewRunState("run_test", "task_test", model.TriggerManual)
+			ctx := withRunLimits(WithRunState(context.Background(), rs), runLimits{ResourceLimits: tt.limits})
+
+			s.Run(ctx)
+			assert.Equal(t, tt.status, rs.Status())
+			if tt.status == model.RunStatusLimitExceeded {
+				assert.Contains(t, readChannel(s.ErrOutput()), "resource limit exceeded")
+			}
+		})
+	}
+}
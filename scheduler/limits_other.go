@@ -0,0 +1,27 @@
+//go:build !linux
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+func setupCgroupRoot(string) error {
+	return fmt.Errorf("cgroup v2 is only supported on linux")
+}
+
+// processLimiter 非 Linux 平台不支持资源限制
+type processLimiter struct{}
+
+func newProcessLimiter(context.Context, *exec.Cmd) (*processLimiter, error) {
+	return nil, nil
+}
+
+func (l *processLimiter) check(_ *os.ProcessState, err error) error {
+	return err
+}
+
+func (l *processLimiter) cleanup() {}
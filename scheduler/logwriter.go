@@ -0,0 +1,345 @@
+package scheduler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/dao/model"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTaskLogQueueSize     = 1024
+	defaultTaskLogBatchSize     = 100
+	defaultTaskLogFlushInterval = time.Second
+	defaultTaskLogJournalPath   = "./logs/task_log.journal"
+
+	// taskLogMaxRetries 每批日志写入失败后的重试次数，仍失败时写入本地暂存文件
+	taskLogMaxRetries = 3
+	// taskLogRetryBackoff 第一次重试前的等待时间，之后每次翻倍
+	taskLogRetryBackoff = 200 * time.Millisecond
+)
+
+// TaskLogStore 批量保存任务日志并更新执行中日志的心跳，由 dao.TaskLogDao 实现
+type TaskLogStore interface {
+	SaveTaskLogs(taskLogs []model.TaskLog) error
+	TouchTaskLogs(runIds []string, at time.Time) error
+}
+
+type unsavedLog struct {
+	ownerName string
+	taskLog   model.TaskLog
+}
+
+// TaskLogWriter 异步批量写入任务日志，避免数据库变慢或不可用时阻塞执行任务的协程
+// 写入失败的日志暂存到本地文件，数据库恢复后重新写入
+type TaskLogWriter struct {
+	log   *zap.SugaredLogger
+	store TaskLogStore
+
+	queue         chan model.TaskLog
+	batchSize     int
+	flushInterval time.Duration
+
+	journalMu   sync.Mutex
+	journalPath string
+	journaled   bool // 本地文件中是否有待重新写入的日志
+
+	// unsaved 已结束但尚未写入数据库的执行日志，运行从 RunRegistry 注销后到写入数据库前据此查询
+	unsavedMu sync.RWMutex
+	unsaved   map[string]unsavedLog // runID -> 日志
+
+	// replayReqs 请求后台协程重新写入暂存文件，所有日志都由后台协程串行写入，避免同一运行ID的日志被并发插入多条
+	replayReqs chan chan struct{}
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	closing   chan struct{}
+	done      chan struct{}
+}
+
+func NewTaskLogWriter(conf *config.ScheduleConfig, store TaskLogStore, log *zap.SugaredLogger) (*TaskLogWriter, error) {
+	var c config.TaskLogConfig
+	if conf.TaskLog != nil {
+		c = *conf.TaskLog
+	}
+	w := &TaskLogWriter{
+		log:           log,
+		store:         store,
+		queue:         make(chan model.TaskLog, defaultIfZero(c.QueueSize, defaultTaskLogQueueSize)),
+		batchSize:     defaultIfZero(c.BatchSize, defaultTaskLogBatchSize),
+		flushInterval: time.Duration(c.FlushInterval) * time.Millisecond,
+		journalPath:   c.JournalPath,
+		unsaved:       make(map[string]unsavedLog),
+		replayReqs:    make(chan chan struct{}),
+		closing:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultTaskLogFlushInterval
+	}
+	if w.journalPath == "" {
+		w.journalPath = defaultTaskLogJournalPath
+	}
+	if err := os.MkdirAll(filepath.Dir(w.journalPath), 0o755); err != nil {
+		return nil, err
+	}
+	// 上次退出时未能写入数据库的日志，在第一次刷新时重新写入
+	if info, err := os.Stat(w.journalPath); err == nil && info.Size() > 0 {
+		w.journaled = true
+	}
+
+	go w.loop()
+	return w, nil
+}
+
+func defaultIfZero(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// Write 将日志放入队列，不会阻塞；队列已满或写入器已关闭时直接写入本地暂存文件
+func (w *TaskLogWriter) Write(taskLog model.TaskLog) {
+	w.mu.RLock()
+	if !w.closed {
+		select {
+		case w.queue <- taskLog:
+			w.mu.RUnlock()
+			return
+		default:
+			w.log.Warnf("task log queue is full, journal log of run %s", taskLog.RunId)
+		}
+	}
+	w.mu.RUnlock()
+	w.journal([]model.TaskLog{taskLog})
+}
+
+// WriteResult 写入执行结束时的日志，写入数据库前可以通过 Unsaved 查询
+func (w *TaskLogWriter) WriteResult(ownerName string, taskLog model.TaskLog) {
+	w.unsavedMu.Lock()
+	w.unsaved[taskLog.RunId] = unsavedLog{ownerName: ownerName, taskLog: taskLog}
+	w.unsavedMu.Unlock()
+	w.Write(taskLog)
+}
+
+// Unsaved 查询用户某次已结束但尚未写入数据库的执行日志，写入失败暂存到本地文件的日志在重新写入前也能查到
+func (w *TaskLogWriter) Unsaved(ownerName, runID string) (model.TaskLog, bool) {
+	w.unsavedMu.RLock()
+	defer w.unsavedMu.RUnlock()
+
+	l, ok := w.unsaved[runID]
+	if !ok || l.ownerName != ownerName {
+		return model.TaskLog{}, false
+	}
+	return l.taskLog, true
+}
+
+// hasUnsaved 某次执行是否已结束但日志尚未写入数据库
+func (w *TaskLogWriter) hasUnsaved(runID string) bool {
+	w.unsavedMu.RLock()
+	defer w.unsavedMu.RUnlock()
+	_, ok := w.unsaved[runID]
+	return ok
+}
+
+// saved 日志写入数据库后不再保留在内存中
+func (w *TaskLogWriter) saved(taskLogs []model.TaskLog) {
+	w.unsavedMu.Lock()
+	defer w.unsavedMu.Unlock()
+
+	for i := range taskLogs {
+		if taskLogs[i].Status != model.RunStatusRunning {
+			delete(w.unsaved, taskLogs[i].RunId)
+		}
+	}
+}
+
+// Touch 同步更新执行中日志的心跳时间，心跳只反映最近的状态，失败时不需要暂存
+func (w *TaskLogWriter) Touch(runIDs []string, at time.Time) error {
+	return w.store.TouchTaskLogs(runIDs, at)
+}
+
+// replayAndWait 由后台协程立即重新写入暂存文件中的日志并等待完成，写入器已关闭时直接返回
+func (w *TaskLogWriter) replayAndWait() {
+	done := make(chan struct{})
+	select {
+	case w.replayReqs <- done:
+		<-done
+	case <-w.done:
+	}
+}
+
+// Close 停止接收新日志，将队列中剩余的日志写入数据库后返回
+func (w *TaskLogWriter) Close() {
+	w.closeOnce.Do(func() {
+		// 持有写锁后不会再有日志进入队列，后台协程可以放心清空队列
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.closing)
+	})
+	<-w.done
+}
+
+func (w *TaskLogWriter) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.TaskLog, 0, w.batchSize)
+	for {
+		select {
+		case taskLog := <-w.queue:
+			batch = append(batch, taskLog)
+			if len(batch) >= w.batchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		case done := <-w.replayReqs:
+			w.replay()
+			close(done)
+		case <-w.closing:
+			// 关闭前写入队列中剩余的日志
+			for len(w.queue) > 0 {
+				batch = append(batch, <-w.queue)
+				if len(batch) >= w.batchSize {
+					w.flush(batch)
+					batch = batch[:0]
+				}
+			}
+			w.flush(batch)
+			return
+		}
+	}
+}
+
+// flush 写入一批日志，失败时按指数退避重试，仍失败则写入本地暂存文件
+// 写入成功说明数据库可用，顺便重新写入暂存文件中的日志
+func (w *TaskLogWriter) flush(batch []model.TaskLog) {
+	if len(batch) > 0 {
+		if err := w.createWithRetry(batch); err != nil {
+			w.log.Errorf("write %d task logs failed, journal them to %s: %v", len(batch), w.journalPath, err)
+			w.journal(batch)
+			return
+		}
+		w.saved(batch)
+	}
+	w.replay()
+}
+
+func (w *TaskLogWriter) createWithRetry(batch []model.TaskLog) error {
+	backoff := taskLogRetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = w.store.SaveTaskLogs(batch); err == nil {
+			return nil
+		}
+		if attempt == taskLogMaxRetries {
+			return err
+		}
+		w.log.Warnf("write %d task logs failed, retry in %v: %v", len(batch), backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// journal 以 JSON Lines 格式追加到本地暂存文件
+func (w *TaskLogWriter) journal(taskLogs []model.TaskLog) {
+	w.journalMu.Lock()
+	defer w.journalMu.Unlock()
+
+	f, err := os.OpenFile(w.journalPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		w.log.Errorf("open task log journal %s failed, %d task logs are lost: %v", w.journalPath, len(taskLogs), err)
+		return
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	for i := range taskLogs {
+		if err := enc.Encode(&taskLogs[i]); err != nil {
+			w.log.Errorf("journal log of run %s failed: %v", taskLogs[i].RunId, err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		w.log.Errorf("write task log journal %s failed: %v", w.journalPath, err)
+		return
+	}
+	w.journaled = true
+}
+
+// replay 取出暂存文件中的日志重新写入数据库，失败的日志放回暂存文件
+func (w *TaskLogWriter) replay() {
+	taskLogs, err := w.takeJournal()
+	if err != nil {
+		w.log.Errorf("read task log journal %s failed: %v", w.journalPath, err)
+		return
+	}
+	if len(taskLogs) == 0 {
+		return
+	}
+
+	for start := 0; start < len(taskLogs); start += w.batchSize {
+		end := min(start+w.batchSize, len(taskLogs))
+		if err := w.store.SaveTaskLogs(taskLogs[start:end]); err != nil {
+			w.log.Errorf("replay task log journal failed, %d task logs are kept: %v", len(taskLogs)-start, err)
+			w.journal(taskLogs[start:])
+			return
+		}
+		w.saved(taskLogs[start:end])
+	}
+	w.log.Infof("replayed %d task logs from journal %s", len(taskLogs), w.journalPath)
+}
+
+// takeJournal 读出并清空暂存文件，无法解析的行会被丢弃
+func (w *TaskLogWriter) takeJournal() ([]model.TaskLog, error) {
+	w.journalMu.Lock()
+	defer w.journalMu.Unlock()
+
+	if !w.journaled {
+		return nil, nil
+	}
+	data, err := os.ReadFile(w.journalPath)
+	if errors.Is(err, os.ErrNotExist) {
+		w.journaled = false
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var taskLogs []model.TaskLog
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for scanner.Scan() {
+		var taskLog model.TaskLog
+		if err := json.Unmarshal(scanner.Bytes(), &taskLog); err != nil {
+			w.log.Errorf("drop malformed task log journal line: %v", err)
+			continue
+		}
+		taskLogs = append(taskLogs, taskLog)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := os.Truncate(w.journalPath, 0); err != nil {
+		return nil, err
+	}
+	w.journaled = false
+	return taskLogs, nil
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeTaskLogStore 在 down 为 true 时模拟数据库不可用
+type fakeTaskLogStore struct {
+	mu    sync.Mutex
+	down  bool
+	saved []model.TaskLog
+}
+
+// SaveTaskLogs 与 dao.TaskLogDao 一致，结束时的记录覆盖同一运行ID的 running 记录
+func (s *fakeTaskLogStore) SaveTaskLogs(taskLogs []model.TaskLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("database is down")
+	}
+next:
+	for _, taskLog := range taskLogs {
+		for i := range s.saved {
+			if s.saved[i].RunId == taskLog.RunId {
+				if taskLog.Status != model.RunStatusRunning {
+					s.saved[i] = taskLog
+				}
+				continue next
+			}
+		}
+		s.saved = append(s.saved, taskLog)
+	}
+	return nil
+}
+
+func (s *fakeTaskLogStore) TouchTaskLogs(runIds []string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.saved {
+		if s.saved[i].Status == model.RunStatusRunning && slices.Contains(runIds, s.saved[i].RunId) {
+			s.saved[i].HeartbeatAt = at
+		}
+	}
+	return nil
+}
+
+func (s *fakeTaskLogStore) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *fakeTaskLogStore) savedRuns() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]string, 0, len(s.saved))
+	for _, l := range s.saved {
+		runs = append(runs, l.RunId)
+	}
+	return runs
+}
+
+func (s *fakeTaskLogStore) find(runID string) (model.TaskLog, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.saved {
+		if l.RunId == runID {
+			return l, true
+		}
+	}
+	return model.TaskLog{}, false
+}
+
+func (s *fakeTaskLogStore) status(runID string) string {
+	l, _ := s.find(runID)
+	return l.Status
+}
+
+func TestTaskLogWriter(t *testing.T) {
+	journal := filepath.Join(t.TempDir(), "task_log.journal")
+	store := &fakeTaskLogStore{down: true}
+	w, err := NewTaskLogWriter(&config.ScheduleConfig{TaskLog: &config.TaskLogConfig{
+		BatchSize:     2,
+		FlushInterval: 50,
+		JournalPath:   journal,
+	}}, store, zap.NewNop().Sugar())
+	assert.NoError(t, err)
+
+	// 数据库不可用时重试失败的日志写入本地文件
+	w.Write(model.TaskLog{RunId: "run_1"})
+	w.Write(model.TaskLog{RunId: "run_2"})
+	assert.Eventually(t, func() bool {
+		info, err := os.Stat(journal)
+		return err == nil && info.Size() > 0
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Empty(t, store.savedRuns())
+
+	// 数据库恢复后重新写入本地文件中的日志
+	store.setDown(false)
+	assert.Eventually(t, func() bool {
+		return len(store.savedRuns()) == 2
+	}, 5*time.Second, 20*time.Millisecond)
+	info, err := os.Stat(journal)
+	assert.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	// 关闭时写入队列中剩余的日志，关闭后的日志写入本地文件
+	w.Write(model.TaskLog{RunId: "run_3"})
+	w.Close()
+	assert.ElementsMatch(t, []string{"run_1", "run_2", "run_3"}, store.savedRuns())
+
+	w.Write(model.TaskLog{RunId: "run_4"})
+	data, err := os.ReadFile(journal)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "run_4")
+}
+
+func TestTaskLogWriter_Unsaved(t *testing.T) {
+	store := &fakeTaskLogStore{down: true}
+	w, err := NewTaskLogWriter(&config.ScheduleConfig{TaskLog: &config.TaskLogConfig{
+		FlushInterval: 20,
+		JournalPath:   filepath.Join(t.TempDir(), "task_log.journal"),
+	}}, store, zap.NewNop().Sugar())
+	assert.NoError(t, err)
+	defer w.Close()
+
+	// 写入数据库前(包括暂存到本地文件期间)可以查到结束的日志，其他用户查不到
+	w.WriteResult("tester", model.TaskLog{RunId: "run_1", Status: model.RunStatusSucceeded})
+	l, ok := w.Unsaved("tester", "run_1")
+	assert.True(t, ok)
+	assert.Equal(t, model.RunStatusSucceeded, l.Status)
+	_, ok = w.Unsaved("other", "run_1")
+	assert.False(t, ok)
+
+	// 写入数据库后不再保留
+	store.setDown(false)
+	assert.Eventually(t, func() bool {
+		_, ok := w.Unsaved("tester", "run_1")
+		return !ok && store.status("run_1") == model.RunStatusSucceeded
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestTaskLogWriter_ReplayAndWait(t *testing.T) {
+	journal := filepath.Join(t.TempDir(), "task_log.journal")
+	store := &fakeTaskLogStore{}
+	w, err := NewTaskLogWriter(&config.ScheduleConfig{TaskLog: &config.TaskLogConfig{
+		FlushInterval: 60000,
+		JournalPath:   journal,
+	}}, store, zap.NewNop().Sugar())
+	assert.NoError(t, err)
+
+	// 暂存文件中的日志在返回前已由后台协程写入
+	w.journal([]model.TaskLog{{RunId: "run_1", Status: model.RunStatusSucceeded}})
+	w.replayAndWait()
+	assert.Equal(t, model.RunStatusSucceeded, store.status("run_1"))
+
+	// 关闭后不会阻塞
+	w.Close()
+	w.replayAndWait()
+}
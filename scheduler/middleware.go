@@ -2,10 +2,11 @@ package scheduler
 
 import (
 	"context"
-	"github.com/chencheng8888/GoDo/dao"
+	"errors"
 	"github.com/chencheng8888/GoDo/dao/model"
 	"github.com/chencheng8888/GoDo/pkg"
 	"go.uber.org/zap"
+	"time"
 )
 
 type Middleware func(next Executor) Executor
@@ -28,38 +29,75 @@ func (l *LogMiddleware) Handler(next Executor) Executor {
 }
 
 type TaskLogMiddleware struct {
-	log        *zap.SugaredLogger
-	taskLogDao *dao.TaskLogDao
+	log    *zap.SugaredLogger
+	writer *TaskLogWriter
 }
 
-func NewTaskLogMiddleware(log *zap.SugaredLogger, taskLogDao *dao.TaskLogDao) *TaskLogMiddleware {
-	return &TaskLogMiddleware{log: log, taskLogDao: taskLogDao}
+func NewTaskLogMiddleware(log *zap.SugaredLogger, writer *TaskLogWriter) *TaskLogMiddleware {
+	return &TaskLogMiddleware{log: log, writer: writer}
 }
 
 func (tl *TaskLogMiddleware) Handler(next Executor) Executor {
 	return func(ctx context.Context, t Task) TaskResult {
-		result := next(ctx, t)
-		output, err := pkg.DetectAndConvertToUTF8([]byte(result.Output))
-		if err != nil {
-			tl.log.Errorf("failed to convert output to utf8: %v", err)
-		}
-		errOutput, err := pkg.DetectAndConvertToUTF8([]byte(result.ErrOutput))
-		if err != nil {
-			tl.log.Errorf("failed to convert output to utf8: %v", err)
-		}
-		taskLog := model.TaskLog{
-			TaskId:    t.id,
-			Name:      t.taskName,
-			Content:   t.f.Content(),
-			Output:    output,
-			ErrOutput: errOutput,
-			StartTime: result.StartTime,
-			EndTime:   result.EndTime,
+		// 开始执行时先写入一条 running 记录，服务异常退出后重启时据此找到被中断的执行
+		if rs, ok := RunStateFromContext(ctx); ok && rs.RunID != "" {
+			now := time.Now()
+			tl.writer.Write(model.TaskLog{
+				TaskId:        t.id,
+				RunId:         rs.RunID,
+				ParentRunId:   rs.ParentRunID,
+				Attempt:       rs.Attempt,
+				Name:          t.taskName,
+				Content:       t.f.Content(),
+				Status:        model.RunStatusRunning,
+				ExitCode:      -1,
+				Trigger:       rs.Trigger,
+				WorkflowRunId: rs.WorkflowRunID,
+				StartTime:     now,
+				EndTime:       now,
+				HeartbeatAt:   now,
+			})
 		}
-		err = tl.taskLogDao.CreateTaskLog(&taskLog)
-		if err != nil {
-			tl.log.Errorf("failed to create task log for task %+v: %v", taskLog, err)
+		result := next(ctx, t)
+		// 服务停止时被终止的执行与用户主动取消区分开
+		if result.Status != model.RunStatusSucceeded && result.Status != model.RunStatusSkipped &&
+			errors.Is(context.Cause(ctx), ErrSchedulerStopped) {
+			result.Status = model.RunStatusInterrupted
 		}
+		tl.record(t, result)
 		return result
 	}
 }
+
+// record 写入一次执行的最终日志，未经过执行链的执行(如被跳过或排队时被取消)也通过它记录
+func (tl *TaskLogMiddleware) record(t Task, result TaskResult) {
+	output, err := pkg.DetectAndConvertToUTF8([]byte(result.Output))
+	if err != nil {
+		tl.log.Errorf("failed to convert output to utf8: %v", err)
+	}
+	errOutput, err := pkg.DetectAndConvertToUTF8([]byte(result.ErrOutput))
+	if err != nil {
+		tl.log.Errorf("failed to convert output to utf8: %v", err)
+	}
+	taskLog := model.TaskLog{
+		TaskId:        t.id,
+		RunId:         result.RunID,
+		ParentRunId:   result.ParentRunID,
+		Attempt:       result.Attempt,
+		Name:          t.taskName,
+		Content:       t.f.Content(),
+		Output:        output,
+		ErrOutput:     errOutput,
+		Status:        result.Status,
+		ExitCode:      result.ExitCode,
+		Trigger:       result.Trigger,
+		WorkflowRunId: result.WorkflowRunID,
+		StartTime:     result.StartTime,
+		EndTime:       result.EndTime,
+		HeartbeatAt:   result.EndTime,
+		Usage:         result.Usage,
+		OutputFile:    result.OutputFile,
+		ErrOutputFile: result.ErrOutputFile,
+	}
+	tl.writer.WriteResult(t.GetOwnerName(), taskLog)
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/robfig/cron/v3"
+)
+
+// 服务停止、任务暂停或进程长时间停顿导致错过触发时间时的处理策略
+const (
+	MisfireSkip    = "skip"     // 跳过错过的触发，从当前时间继续调度
+	MisfireRunOnce = "run_once" // 无论错过多少次都只补执行一次
+	MisfireRunAll  = "run_all"  // 每个错过的触发时间补执行一次，最多补执行 misfireLimit 次
+)
+
+const (
+	// misfireThreshold 触发晚于计划时间不超过该值时视为正常的调度延迟，不算错过
+	misfireThreshold = time.Second
+
+	// DefaultMisfireLimit run_all 策略未设置上限时最多补执行的次数
+	DefaultMisfireLimit = 10
+	// MaxMisfireLimit run_all 策略允许设置的最大补执行次数
+	MaxMisfireLimit = 100
+)
+
+func validateMisfirePolicy(policy string, limit int) error {
+	switch policy {
+	case "", MisfireSkip, MisfireRunOnce, MisfireRunAll:
+	default:
+		return fmt.Errorf("misfire policy %q unknown", policy)
+	}
+	if limit < 0 || limit > MaxMisfireLimit {
+		return fmt.Errorf("misfire limit must be in [0, %d]", MaxMisfireLimit)
+	}
+	return nil
+}
+
+// missedFireTimes 返回 (since, until] 之间的触发时间，最多 limit 个，more 表示还有更多没有返回
+func missedFireTimes(sche cron.Schedule, since, until time.Time, limit int) (missed []time.Time, more bool) {
+	for t := sche.Next(since); !t.IsZero() && !t.After(until); t = sche.Next(t) {
+		if len(missed) == limit {
+			return missed, true
+		}
+		missed = append(missed, t)
+	}
+	return missed, false
+}
+
+// fireTracker 记录 cron 调度项上一次触发的时间，用于发现进程停顿或系统休眠期间错过的触发
+// cron 在停顿恢复后只触发一次到期的调度项，并从当前时间重新计算下一次触发时间，中间的触发被直接跳过
+type fireTracker struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+func newFireTracker(now time.Time) *fireTracker {
+	return &fireTracker{last: now}
+}
+
+// fire 记录本次触发并返回本次触发之后被 cron 跳过的触发时间
+func (f *fireTracker) fire(sche cron.Schedule, now time.Time, limit int) ([]time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// 本次触发对应上一次触发之后的第一个触发时间
+	current := sche.Next(f.last)
+	f.last = now
+	if current.IsZero() {
+		return nil, false
+	}
+	return missedFireTimes(sche, current, now.Add(-misfireThreshold), limit)
+}
+
+// misfireScanLimit 按任务的策略返回需要找出的错过触发的数量
+func misfireScanLimit(t Task) int {
+	if t.GetMisfirePolicy() != MisfireRunAll {
+		return 1
+	}
+	if t.GetMisfireLimit() <= 0 {
+		return DefaultMisfireLimit
+	}
+	return t.GetMisfireLimit()
+}
+
+// catchUp 按任务的策略补执行错过的触发，补执行依次进行，触发来源记为 catch_up
+func (s *CronScheduler) catchUp(t Task, missed []time.Time, more bool) {
+	if len(missed) == 0 {
+		return
+	}
+
+	runs := 0
+	switch t.GetMisfirePolicy() {
+	case MisfireRunOnce:
+		runs = 1
+	case MisfireRunAll:
+		runs = len(missed)
+	}
+	count := fmt.Sprintf("%d", len(missed))
+	if more {
+		count += "+"
+	}
+	s.log.Warnf("⏰ task[%s] missed %s fire times since %s, misfire policy %s, catch up %d runs",
+		t.GetID(), count, missed[0].Format(time.DateTime), t.GetMisfirePolicy(), runs)
+	if runs == 0 {
+		return
+	}
+
+	s.catchUpRuns(t, runs)
+}
+
+// catchUpRuns 按正常的提交流程提交一次补执行，结束后再提交下一次，等待期间不占用协程
+func (s *CronScheduler) catchUpRuns(t Task, runs int) {
+	e, err := s.newRun(t, model.TriggerCatchUp, s.startWorkflow(t))
+	if err != nil {
+		s.log.Errorf("catch up task[%s] failed: %v", t.GetID(), err)
+		return
+	}
+	if runs > 1 {
+		e.then = func() { s.catchUpRuns(t, runs-1) }
+	}
+	s.dispatch(e)
+}
+
+// catchUpMissed 找出 (since, now] 之间错过的触发并按任务的策略补执行，返回是否有错过的触发
+// 补执行后将 now 记为上一次触发时间，避免再次重启时重复补执行
+func (s *CronScheduler) catchUpMissed(t Task, since, now time.Time) bool {
+	if t.IsDownstream() {
+		return false
+	}
+	sche, err := parseSchedule(t.GetScheduleType(), t.GetScheduledTime(), t.GetTimeZone(), s.parser)
+	if err != nil {
+		return false
+	}
+	missed, more := missedFireTimes(sche, since, now, misfireScanLimit(t))
+	if len(missed) == 0 {
+		return false
+	}
+	if err := s.taskInfoDao.UpdateLastFireTime(t.GetID(), now); err != nil {
+		s.log.Errorf("update last fire time of task[%s] failed: %v", t.GetID(), err)
+	}
+	s.catchUp(t, missed, more)
+	return true
+}
+
+// lastFireTime 任务上一次被调度触发的时间，从未触发过时使用任务的创建时间
+func lastFireTime(taskInfo *model.TaskInfo) time.Time {
+	if taskInfo.LastFireTime != nil {
+		return *taskInfo.LastFireTime
+	}
+	return taskInfo.CreatedAt
+}
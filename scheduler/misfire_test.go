@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissedFireTimes(t *testing.T) {
+	sche := EverySchedule{Interval: time.Hour, Location: time.UTC}
+	since := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	missed, more := missedFireTimes(sche, since, until, 10)
+	assert.False(t, more)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}, missed)
+
+	missed, more = missedFireTimes(sche, since, until, 2)
+	assert.True(t, more)
+	assert.Len(t, missed, 2)
+
+	// 单次任务的执行时间已过
+	at := AtSchedule{At: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	missed, _ = missedFireTimes(at, since, until, 1)
+	assert.Len(t, missed, 1)
+	missed, _ = missedFireTimes(at, until, until.Add(time.Hour), 1)
+	assert.Empty(t, missed)
+}
+
+func TestFireTracker(t *testing.T) {
+	sche := EverySchedule{Interval: time.Minute, Location: time.UTC}
+	start := time.Date(2026, 10, 1, 8, 0, 30, 0, time.UTC)
+	f := newFireTracker(start)
+
+	// 按时触发
+	missed, _ := f.fire(sche, time.Date(2026, 10, 1, 8, 1, 0, 1000, time.UTC), 10)
+	assert.Empty(t, missed)
+
+	// 停顿了5分钟，cron 恢复后只触发一次 08:02，08:03 到 08:06 被跳过
+	missed, _ = f.fire(sche, time.Date(2026, 10, 1, 8, 6, 30, 0, time.UTC), 10)
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 10, 1, 8, 3, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 8, 4, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 8, 5, 0, 0, time.UTC),
+		time.Date(2026, 10, 1, 8, 6, 0, 0, time.UTC),
+	}, missed)
+
+	missed, _ = f.fire(sche, time.Date(2026, 10, 1, 8, 7, 0, 0, time.UTC), 10)
+	assert.Empty(t, missed)
+}
+
+func TestCronScheduler_CatchUp(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	defer s.Stop()
+
+	now := time.Now()
+	missed := []time.Time{now.Add(-3 * time.Hour), now.Add(-2 * time.Hour), now.Add(-time.Hour)}
+	newTask := func(policy string) Task {
+		return NewTask("task_"+policy, policy, "tester", "@every 1h", "",
+			NewShellJob(true, 5*time.Second, t.TempDir(), "tester", "true"),
+			WithMisfirePolicy(policy, 0))
+	}
+
+	s.catchUp(newTask(MisfireSkip), missed, false)
+	s.catchUp(newTask(MisfireRunOnce), missed, false)
+	s.catchUp(newTask(MisfireRunAll), missed, false)
+
+	// run_all 每个错过的触发补执行一次，run_once 只补执行一次，补执行的触发来源为 catch_up
+	assert.Eventually(t, func() bool {
+		return len(store.savedRuns()) == 4 && s.Health().Running == 0
+	}, 5*time.Second, 20*time.Millisecond)
+	counts := make(map[string]int)
+	var runAll []model.TaskLog
+	for _, runID := range store.savedRuns() {
+		l, _ := store.find(runID)
+		assert.Equal(t, model.TriggerCatchUp, l.Trigger)
+		counts[l.TaskId]++
+		if l.TaskId == "task_run_all" {
+			runAll = append(runAll, l)
+		}
+	}
+	assert.Equal(t, map[string]int{"task_run_once": 1, "task_run_all": 3}, counts)
+
+	// 同一任务的补执行依次进行，上一次结束后才开始下一次
+	sort.Slice(runAll, func(i, j int) bool { return runAll[i].StartTime.Before(runAll[j].StartTime) })
+	for i := 1; i < len(runAll); i++ {
+		assert.False(t, runAll[i].StartTime.Before(runAll[i-1].EndTime))
+	}
+
+	assert.Error(t, validateMisfirePolicy("later", 0))
+	assert.Error(t, validateMisfirePolicy(MisfireRunAll, MaxMisfireLimit+1))
+}
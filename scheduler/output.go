@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	OutputStreamStdout = "stdout"
+	OutputStreamStderr = "stderr"
+
+	// 为晚到的订阅者保留的最近输出行数
+	outputHistorySize = 500
+	// 单个订阅者的缓冲区大小，订阅者消费过慢时丢弃新的输出行
+	outputSubscriberBuffer = 256
+
+	// maxOutputLineSize 单行输出的最大长度，超过时不等换行直接按一行推送，避免不换行的输出无限占用内存
+	maxOutputLineSize = 64 << 10
+	// outputHistoryLineSize 为晚到的订阅者保留的历史输出每行的最大长度
+	outputHistoryLineSize = 4 << 10
+)
+
+// OutputLine 任务执行过程中产生的一行输出
+type OutputLine struct {
+	Stream string    `json:"stream"` // stdout/stderr
+	Line   string    `json:"line"`
+	Time   time.Time `json:"time"`
+}
+
+// outputBroadcaster 将一次执行的输出逐行推送给订阅者
+type outputBroadcaster struct {
+	mu      sync.Mutex
+	subs    map[chan OutputLine]struct{}
+	history []OutputLine
+	closed  bool
+}
+
+func newOutputBroadcaster() *outputBroadcaster {
+	return &outputBroadcaster{
+		subs: make(map[chan OutputLine]struct{}),
+	}
+}
+
+func (b *outputBroadcaster) publish(line OutputLine) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	stored := line
+	stored.Line = truncateLine(line.Line, outputHistoryLineSize)
+	b.history = append(b.history, stored)
+	if len(b.history) > outputHistorySize {
+		b.history = b.history[len(b.history)-outputHistorySize:]
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+}
+
+// subscribe 订阅输出，先补发最近的历史输出，执行结束后通道会被关闭
+func (b *outputBroadcaster) subscribe() (<-chan OutputLine, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan OutputLine, len(b.history)+outputSubscriberBuffer)
+	for _, line := range b.history {
+		ch <- line
+	}
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	b.subs[ch] = struct{}{}
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// recent 按输出类型拼接保留的最近输出
+func (b *outputBroadcaster) recent() (stdout, stderr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out, errOut strings.Builder
+	for _, line := range b.history {
+		w := &out
+		if line.Stream == OutputStreamStderr {
+			w = &errOut
+		}
+		w.WriteString(line.Line)
+		w.WriteByte('\n')
+	}
+	return out.String(), errOut.String()
+}
+
+// close 结束推送并关闭所有订阅通道，可重复调用
+func (b *outputBroadcaster) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
+
+// truncateLine 将超过 limit 的行截断，截断处不拆开 UTF-8 多字节字符
+func truncateLine(line string, limit int) string {
+	if len(line) <= limit {
+		return line
+	}
+	cut := runeBoundary([]byte(line), limit)
+	return fmt.Sprintf("%s ... [%d bytes truncated]", line[:cut], len(line)-cut)
+}
+
+// runeBoundary 返回不超过 n 且不拆开 UTF-8 多字节字符的切分位置
+func runeBoundary(p []byte, n int) int {
+	if n >= len(p) {
+		return len(p)
+	}
+	for i := n; i > n-utf8.UTFMax && i > 0; i-- {
+		if utf8.RuneStart(p[i]) {
+			return i
+		}
+	}
+	return n
+}
+
+// lineWriter 将写入的内容完整保存到 buf，同时按行回调 onLine，超过 maxOutputLineSize 的行会被拆成多行
+type lineWriter struct {
+	buf     io.Writer
+	partial []byte
+	onLine  func(line string)
+}
+
+func newLineWriter(buf io.Writer, onLine func(line string)) *lineWriter {
+	return &lineWriter{buf: buf, onLine: onLine}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n, err := w.buf.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(string(bytes.TrimRight(w.partial[:i], "\r")))
+		w.partial = w.partial[i+1:]
+	}
+	for len(w.partial) >= maxOutputLineSize {
+		cut := runeBoundary(w.partial, maxOutputLineSize)
+		w.onLine(string(w.partial[:cut]))
+		w.partial = w.partial[cut:]
+	}
+	// 一次写入的内容很大时，释放已推送部分占用的底层数组
+	if cap(w.partial) > 2*maxOutputLineSize {
+		w.partial = append([]byte(nil), w.partial...)
+	}
+	return n, nil
+}
+
+// Flush 推送最后一行不以换行结尾的输出
+func (w *lineWriter) Flush() {
+	if len(w.partial) > 0 {
+		w.onLine(string(w.partial))
+		w.partial = nil
+	}
+}
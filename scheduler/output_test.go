@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var lines []string
+	w := newLineWriter(&buf, func(line string) {
+		lines = append(lines, line)
+	})
+
+	_, _ = w.Write([]byte("hello\r\nwor"))
+	_, _ = w.Write([]byte("ld\npartial"))
+	assert.Equal(t, []string{"hello", "world"}, lines)
+
+	w.Flush()
+	assert.Equal(t, []string{"hello", "world", "partial"}, lines)
+	assert.Equal(t, "hello\r\nworld\npartial", buf.String())
+}
+
+func TestOutputBroadcaster(t *testing.T) {
+	b := newOutputBroadcaster()
+	b.publish(OutputLine{Stream: OutputStreamStdout, Line: "early"})
+
+	// 晚到的订阅者先收到历史输出
+	ch, unsubscribe := b.subscribe()
+	defer unsubscribe()
+	b.publish(OutputLine{Stream: OutputStreamStderr, Line: "late"})
+	b.close()
+
+	var got []OutputLine
+	for line := range ch {
+		got = append(got, line)
+	}
+	assert.Equal(t, []OutputLine{
+		{Stream: OutputStreamStdout, Line: "early"},
+		{Stream: OutputStreamStderr, Line: "late"},
+	}, got)
+
+	// 结束后订阅仍可拿到历史输出，通道立即关闭
+	ch, _ = b.subscribe()
+	assert.Len(t, ch, 2)
+
+	// 执行中查询时按输出类型拼接最近的输出
+	stdout, stderr := b.recent()
+	assert.Equal(t, "early\n", stdout)
+	assert.Equal(t, "late\n", stderr)
+}
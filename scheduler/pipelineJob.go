@@ -0,0 +1,371 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+const (
+	PipelineJobType = "pipeline"
+)
+
+// PipelineStep 流水线中的一个步骤
+type PipelineStep struct {
+	Name            string        `json:"name"`              // 步骤名称
+	Command         string        `json:"command"`           // 执行命令
+	Args            []string      `json:"args"`              // 命令参数
+	Timeout         time.Duration `json:"timeout"`           // 单个步骤的超时时间
+	ContinueOnError bool          `json:"continue_on_error"` // 步骤失败后是否继续执行后续步骤
+}
+
+// PipelineStepResult 单个步骤的执行结果
+type PipelineStepResult struct {
+	Name      string
+	Status    string // 执行状态，未执行的步骤为 model.RunStatusSkipped
+	ExitCode  int
+	Duration  time.Duration
+	Output    string
+	ErrOutput string
+}
+
+// PipelineJob 在同一工作目录中按顺序执行多条命令
+type PipelineJob struct {
+	Steps    []PipelineStep `json:"steps"`
+	UseShell bool           `json:"use_shell"` // 是否通过系统默认 Shell 执行各步骤
+	// Env 各步骤共用的环境变量，值中可以通过 ${secret:NAME} 引用用户密钥
+	Env map[string]string `json:"env,omitempty"`
+	// Limits 每个步骤的资源限制，覆盖用户的默认限制但不能超过它
+	Limits    *model.ResourceLimits `json:"limits,omitempty"`
+	output    chan string           // 标准输出
+	errOutput chan string           // 错误输出
+
+	workDir string // 工作目录
+
+	userName string // 用户名
+}
+
+func NewPipelineJob(useShell bool, workDir, userName string, steps ...PipelineStep) *PipelineJob {
+	return &PipelineJob{
+		Steps:     steps,
+		UseShell:  useShell,
+		output:    make(chan string, 100),
+		errOutput: make(chan string, 100),
+		workDir:   workDir,
+		userName:  userName,
+	}
+}
+
+func (p *PipelineJob) Type() string {
+	return PipelineJobType
+}
+
+const pipelineJobSchema = `{
+  "type": "object",
+  "required": ["steps"],
+  "additionalProperties": false,
+  "properties": {
+    "use_shell": {"type": "boolean", "description": "是否使用Shell执行各步骤"},
+    "env": {"type": "object", "maxProperties": 50, "additionalProperties": {"type": "string"}, "description": "各步骤共用的环境变量，值中可通过 ${secret:NAME} 引用密钥"},
+    "limits": ` + resourceLimitsSchema + `,
+    "steps": {
+      "type": "array",
+      "minItems": 1,
+      "maxItems": 20,
+      "description": "按顺序执行的步骤",
+      "items": {
+        "type": "object",
+        "required": ["command", "timeout"],
+        "additionalProperties": false,
+        "properties": {
+          "name": {"type": "string", "maxLength": 64, "description": "步骤名称"},
+          "command": {"type": "string", "minLength": 1, "description": "执行命令"},
+          "args": {"type": "array", "items": {"type": "string"}, "description": "命令参数"},
+          "timeout": {"type": "integer", "exclusiveMinimum": 0, "maximum": 7200, "description": "步骤超时时间(秒)"},
+          "continue_on_error": {"type": "boolean", "description": "步骤失败后是否继续执行后续步骤"}
+        }
+      }
+    }
+  }
+}`
+
+// pipelineJobSpec 创建流水线任务时 job 字段的结构
+type pipelineJobSpec struct {
+	UseShell bool                  `json:"use_shell"`
+	Env      map[string]string     `json:"env" binding:"omitempty,max=50"`
+	Limits   *model.ResourceLimits `json:"limits"`
+	Steps    []struct {
+		Name            string   `json:"name" binding:"omitempty,max=64"`
+		Command         string   `json:"command" binding:"required"`
+		Args            []string `json:"args"`
+		Timeout         int      `json:"timeout" binding:"required,max=7200,gt=0"`
+		ContinueOnError bool     `json:"continue_on_error"`
+	} `json:"steps" binding:"required,min=1,max=20,dive"`
+}
+
+func bindPipelineJob(bc JobBindContext, raw json.RawMessage) (Job, error) {
+	var spec pipelineJobSpec
+	if err := bindJobSpec(raw, &spec); err != nil {
+		return nil, err
+	}
+	if spec.UseShell && !bc.UseShell {
+		return nil, fmt.Errorf("the user is not allowed to use shell to run commands")
+	}
+	steps := make([]PipelineStep, 0, len(spec.Steps))
+	for _, step := range spec.Steps {
+		steps = append(steps, PipelineStep{
+			Name:            step.Name,
+			Command:         step.Command,
+			Args:            step.Args,
+			Timeout:         time.Duration(step.Timeout) * time.Second,
+			ContinueOnError: step.ContinueOnError,
+		})
+	}
+	job := NewPipelineJob(spec.UseShell, bc.WorkDir, bc.UserName, steps...)
+	job.Env = spec.Env
+	job.Limits = spec.Limits
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Validate 校验环境变量名
+func (p *PipelineJob) Validate() error {
+	return validateEnv(p.Env)
+}
+
+func (p *PipelineJob) GetLimits() *model.ResourceLimits {
+	return p.Limits
+}
+
+// SecretRefs 返回各步骤命令、参数和环境变量中引用的密钥
+func (p *PipelineJob) SecretRefs() []string {
+	values := envValues(p.Env)
+	for _, step := range p.Steps {
+		values = append(append(values, step.Command), step.Args...)
+	}
+	return secretRefs(values...)
+}
+
+func (p *PipelineJob) Content() string {
+	if p == nil {
+		return ""
+	}
+
+	type step struct {
+		Name            string   `json:"name"`
+		Command         string   `json:"command"`
+		Args            []string `json:"args"`
+		TimeOut         string   `json:"timeout"`
+		ContinueOnError bool     `json:"continue_on_error"`
+	}
+	type result struct {
+		Steps    []step                `json:"steps"`
+		UseShell bool                  `json:"use_shell"`
+		Env      map[string]string     `json:"env,omitempty"` // 密钥以引用形式展示，不会包含明文
+		Limits   *model.ResourceLimits `json:"limits,omitempty"`
+	}
+
+	res := result{UseShell: p.UseShell, Env: p.Env, Limits: p.Limits}
+	for _, s := range p.Steps {
+		res.Steps = append(res.Steps, step{
+			Name:            s.Name,
+			Command:         s.Command,
+			Args:            s.Args,
+			TimeOut:         s.Timeout.String(),
+			ContinueOnError: s.ContinueOnError,
+		})
+	}
+
+	resStr, _ := json.Marshal(res)
+	return string(resStr)
+}
+
+func (p *PipelineJob) Run(ctx context.Context) {
+	if len(p.workDir) == 0 || len(p.userName) == 0 {
+		p.errOutput <- "your user name is empty"
+		return
+	}
+	dir := filepath.Join(p.workDir, p.userName)
+	if err := prepareWorkDir(ctx, dir); err != nil {
+		p.errOutput <- "dir not found"
+		return
+	}
+
+	rs, hasRunState := RunStateFromContext(ctx)
+
+	results := make([]PipelineStepResult, 0, len(p.Steps))
+	var (
+		status   = model.RunStatusSucceeded
+		exitCode = 0
+		failure  string
+	)
+
+	for i, step := range p.Steps {
+		name := p.stepName(i)
+		if status != model.RunStatusSucceeded {
+			results = append(results, PipelineStepResult{Name: name, Status: model.RunStatusSkipped, ExitCode: -1})
+			continue
+		}
+
+		if hasRunState {
+			rs.PublishOutput(OutputStreamStdout, fmt.Sprintf("==> [%d/%d] %s", i+1, len(p.Steps), name))
+		}
+
+		result := p.runStep(ctx, dir, name, step)
+		results = append(results, result)
+		exitCode = result.ExitCode
+
+		if result.Status == model.RunStatusSucceeded {
+			continue
+		}
+		if ctx.Err() != nil {
+			// 整个流水线被取消或超时，不再区分步骤策略
+			status = model.RunStatusCancelled
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				status = model.RunStatusTimedOut
+			}
+		} else if !step.ContinueOnError {
+			status = result.Status
+		}
+		failure += fmt.Sprintf("step %d/%d (%s) %s: %s\n", i+1, len(p.Steps), name, result.Status, result.ErrOutput)
+	}
+
+	if hasRunState {
+		rs.SetExitCode(exitCode)
+		rs.SetStatus(status)
+	}
+
+	p.output <- formatStepResults(results)
+	if status != model.RunStatusSucceeded {
+		p.errOutput <- failure
+	}
+}
+
+// runStep 执行单个步骤，步骤超时只影响当前步骤
+func (p *PipelineJob) runStep(ctx context.Context, dir, name string, step PipelineStep) PipelineStepResult {
+	stepCtx, cancel := context.WithTimeout(ctx, step.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	cmd, err := newJobCommand(stepCtx, p.UseShell, step.Command, step.Args, p.Env)
+	if err != nil {
+		return PipelineStepResult{
+			Name:      name,
+			Status:    model.RunStatusFailed,
+			ExitCode:  -1,
+			ErrOutput: err.Error(),
+		}
+	}
+	cmd.Dir = dir
+
+	stdout, stderr, err := runCommand(ctx, cmd)
+	result := PipelineStepResult{
+		Name:      name,
+		Status:    model.RunStatusSucceeded,
+		ExitCode:  -1,
+		Duration:  time.Since(start),
+		Output:    stdout,
+		ErrOutput: stderr,
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrLimitExceeded):
+		result.Status = model.RunStatusLimitExceeded
+	case errors.Is(stepCtx.Err(), context.DeadlineExceeded):
+		result.Status = model.RunStatusTimedOut
+	case errors.Is(stepCtx.Err(), context.Canceled):
+		result.Status = model.RunStatusCancelled
+	default:
+		result.Status = model.RunStatusFailed
+	}
+	if err != nil && result.ErrOutput == "" {
+		result.ErrOutput = describeCommandError(stepCtx, step.Timeout, err)
+	}
+	return result
+}
+
+func (p *PipelineJob) stepName(i int) string {
+	if p.Steps[i].Name != "" {
+		return p.Steps[i].Name
+	}
+	return fmt.Sprintf("step-%d", i+1)
+}
+
+// formatStepResults 将各步骤的状态、耗时和输出整理为运行日志中的文本
+func formatStepResults(results []PipelineStepResult) string {
+	var b strings.Builder
+	for i, r := range results {
+		if r.Status == model.RunStatusSkipped {
+			fmt.Fprintf(&b, "==> [%d/%d] %s: %s\n", i+1, len(results), r.Name, r.Status)
+			continue
+		}
+		fmt.Fprintf(&b, "==> [%d/%d] %s: %s (exit code %d, duration %s)\n",
+			i+1, len(results), r.Name, r.Status, r.ExitCode, r.Duration.Round(time.Millisecond))
+		b.WriteString(r.Output)
+		if r.ErrOutput != "" {
+			b.WriteString("--- stderr ---\n")
+			b.WriteString(r.ErrOutput)
+		}
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (p *PipelineJob) Output() <-chan string {
+	return p.output
+}
+
+func (p *PipelineJob) ErrOutput() <-chan string {
+	return p.errOutput
+}
+
+func (p *PipelineJob) ToJson() string {
+	type Alias PipelineJob // 防止递归调用
+	res, _ := json.Marshal(&struct {
+		WorkDir  string `json:"work_dir"`
+		UserName string `json:"user_name"`
+		*Alias
+	}{
+		WorkDir:  p.workDir,
+		UserName: p.userName,
+		Alias:    (*Alias)(p),
+	})
+	return string(res)
+}
+
+func (p *PipelineJob) UnmarshalFromJson(jsonStr string) error {
+	if p == nil {
+		return fmt.Errorf("cannot unmarshall from json: nil pointer")
+	}
+
+	type Alias PipelineJob
+	aux := &struct {
+		WorkDir  string `json:"work_dir"`
+		UserName string `json:"user_name"`
+		*Alias
+	}{
+		Alias: (*Alias)(p),
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &aux); err != nil {
+		return err
+	}
+	p.workDir = aux.WorkDir
+	p.userName = aux.UserName
+	p.output = make(chan string, 100)
+	p.errOutput = make(chan string, 100)
+	return nil
+}
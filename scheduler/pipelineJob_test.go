@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineJob_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    []PipelineStep
+		status   string
+		exitCode int
+		contains []string
+	}{
+		{
+			name: "all steps succeed",
+			steps: []PipelineStep{
+				{Name: "dump", Command: "echo", Args: []string{"dumped"}, Timeout: 5 * time.Second},
+				{Name: "compress", Command: "echo", Args: []string{"compressed"}, Timeout: 5 * time.Second},
+			},
+			status:   model.RunStatusSucceeded,
+			exitCode: 0,
+			contains: []string{"[1/2] dump: succeeded", "dumped", "[2/2] compress: succeeded", "compressed"},
+		},
+		{
+			name: "continue on error",
+			steps: []PipelineStep{
+				{Name: "cleanup", Command: "exit 3", Timeout: 5 * time.Second, ContinueOnError: true},
+				{Name: "upload", Command: "echo", Args: []string{"uploaded"}, Timeout: 5 * time.Second},
+			},
+			status:   model.RunStatusSucceeded,
+			exitCode: 0,
+			contains: []string{"[1/2] cleanup: failed (exit code 3", "[2/2] upload: succeeded", "uploaded"},
+		},
+		{
+			name: "stop on error",
+			steps: []PipelineStep{
+				{Name: "compress", Command: "exit 2", Timeout: 5 * time.Second},
+				{Name: "upload", Command: "echo", Args: []string{"uploaded"}, Timeout: 5 * time.Second},
+			},
+			status:   model.RunStatusFailed,
+			exitCode: 2,
+			contains: []string{"[1/2] compress: failed (exit code 2", "[2/2] upload: skipped"},
+		},
+		{
+			name: "step timeout",
+			steps: []PipelineStep{
+				{Name: "slow", Command: "sleep", Args: []string{"5"}, Timeout: 100 * time.Millisecond},
+			},
+			status:   model.RunStatusTimedOut,
+			exitCode: -1,
+			contains: []string{"[1/1] slow: timed_out"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPipelineJob(true, "..", ".", tt.steps...)
+			rs := NewRunState("run_test", "task_test", model.TriggerManual)
+			p.Run(WithRunState(context.Background(), rs))
+
+			assert.Equal(t, tt.status, rs.Status())
+			assert.Equal(t, tt.exitCode, rs.ExitCode())
+
+			output := readChannel(p.Output())
+			for _, s := range tt.contains {
+				assert.Contains(t, output, s)
+			}
+			if tt.status == model.RunStatusSucceeded {
+				assert.Empty(t, readChannel(p.ErrOutput()))
+			} else {
+				assert.NotEmpty(t, readChannel(p.ErrOutput()))
+			}
+		})
+	}
+}
+
+func TestPipelineJob_Json(t *testing.T) {
+	p := NewPipelineJob(false, "/data", "alice",
+		PipelineStep{Name: "dump", Command: "pg_dump", Args: []string{"db"}, Timeout: time.Minute, ContinueOnError: true})
+
+	restored := new(PipelineJob)
+	assert.NoError(t, restored.UnmarshalFromJson(p.ToJson()))
+	assert.Equal(t, p.Steps, restored.Steps)
+	assert.Equal(t, "/data", restored.workDir)
+	assert.Equal(t, "alice", restored.userName)
+}
@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultKillGracePeriod 超时或取消时，发送 SIGTERM 后等待进程自行退出的默认时间
+	DefaultKillGracePeriod = 10 * time.Second
+)
+
+type killGracePeriodKey struct{}
+
+// WithKillGracePeriod 设置超时或取消时从 SIGTERM 到 SIGKILL 的等待时间
+func WithKillGracePeriod(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, killGracePeriodKey{}, d)
+}
+
+func killGracePeriodFromContext(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(killGracePeriodKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+	return DefaultKillGracePeriod
+}
@@ -0,0 +1,75 @@
+//go:build !windows
+
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+// setProcessGroup 让命令在独立的进程组中运行，超时或取消时先向整个进程组发送 SIGTERM，
+// 超过 grace 仍未退出且进程尚未被回收时再发送 SIGKILL，避免 Shell 启动的子进程在任务结束后继续运行
+func setProcessGroup(cmd *exec.Cmd, grace time.Duration) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		if err := killProcessGroup(pgid, syscall.SIGTERM); err != nil {
+			return err
+		}
+		time.AfterFunc(grace, func() {
+			// 进程已被 Wait 回收时进程组ID可能已被其他进程复用，不能再发送信号；
+			// 未回收的进程(包括僵尸进程)仍占用该ID，此时发送是安全的
+			if errors.Is(cmd.Process.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+				return
+			}
+			_ = killProcessGroup(pgid, syscall.SIGKILL)
+		})
+		return nil
+	}
+	// 进程组被杀死后输出管道会关闭，这里只兜底脱离进程组后仍占用管道的进程
+	cmd.WaitDelay = grace + time.Second
+}
+
+func killProcessGroup(pgid int, sig syscall.Signal) error {
+	err := syscall.Kill(-pgid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// setCredential 让命令以指定的系统用户运行，并清空附加组，避免继承服务所在的组
+func setCredential(cmd *exec.Cmd, ra RunAs) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: ra.UID, Gid: ra.GID, Groups: []uint32{}}
+}
+
+// processUsage 读取进程结束时的 rusage
+func processUsage(state *os.ProcessState) model.ResourceUsage {
+	usage := model.ResourceUsage{
+		UserCPUMs:   state.UserTime().Milliseconds(),
+		SystemCPUMs: state.SystemTime().Milliseconds(),
+	}
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return usage
+	}
+	usage.MaxRSSKB = int64(ru.Maxrss)
+	if runtime.GOOS == "darwin" {
+		// macOS 的 ru_maxrss 单位为字节
+		usage.MaxRSSKB /= 1024
+	}
+	usage.InBlocks = int64(ru.Inblock)
+	usage.OutBlocks = int64(ru.Oublock)
+	usage.VoluntaryCtxSwitches = int64(ru.Nvcsw)
+	usage.InvoluntaryCtxSwitches = int64(ru.Nivcsw)
+	return usage
+}
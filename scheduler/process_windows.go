@@ -0,0 +1,27 @@
+//go:build windows
+
+package scheduler
+
+import (
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+// setProcessGroup Windows 下没有进程组信号，超时或取消时直接结束进程，grace 仅用于等待输出管道关闭
+func setProcessGroup(cmd *exec.Cmd, grace time.Duration) {
+	cmd.WaitDelay = grace
+}
+
+// setCredential Windows 下不支持切换用户，NewRunAsMapper 会拒绝 run_as 配置
+func setCredential(*exec.Cmd, RunAs) {}
+
+// processUsage Windows 下只有 CPU 时间
+func processUsage(state *os.ProcessState) model.ResourceUsage {
+	return model.ResourceUsage{
+		UserCPUMs:   state.UserTime().Milliseconds(),
+		SystemCPUMs: state.SystemTime().Milliseconds(),
+	}
+}
@@ -0,0 +1,275 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	RunningStatusPending = "pending" // 已提交，等待执行
+	RunningStatusRunning = "running" // 执行中
+)
+
+// RunningInfo 正在执行中的任务信息
+type RunningInfo struct {
+	RunID         string    `json:"run_id"`
+	Status        string    `json:"status"` // pending/running
+	TaskID        string    `json:"task_id"`
+	TaskName      string    `json:"task_name"`
+	OwnerName     string    `json:"owner_name"`
+	Trigger       string    `json:"trigger"`
+	Attempt       int       `json:"attempt"`
+	WorkflowRunID string    `json:"workflow_run_id,omitempty"` // 所属工作流运行ID
+	StartTime     time.Time `json:"start_time"`
+	PID           int       `json:"pid"` // 进程ID，非进程类任务或尚未启动时为 0
+
+	// 最近的输出，只在查询单次运行时返回
+	Output    string `json:"output,omitempty"`
+	ErrOutput string `json:"err_output,omitempty"`
+}
+
+type runningRun struct {
+	task   Task
+	state  *RunState
+	cancel context.CancelFunc
+}
+
+func (r *runningRun) info(status string) RunningInfo {
+	return RunningInfo{
+		RunID:         r.state.RunID,
+		Status:        status,
+		TaskID:        r.task.GetID(),
+		TaskName:      r.task.GetTaskName(),
+		OwnerName:     r.task.GetOwnerName(),
+		Trigger:       r.state.Trigger,
+		Attempt:       r.state.Attempt,
+		WorkflowRunID: r.state.WorkflowRunID,
+		StartTime:     r.state.StartTime,
+		PID:           r.state.PID(),
+	}
+}
+
+// taskExecutions 同一任务已开始的执行和按并发策略排队等待开始的执行
+type taskExecutions struct {
+	active map[*execution]struct{}
+	queue  []*execution
+}
+
+// admission 按并发策略对一次执行的处理结果
+type admission int
+
+const (
+	admitStart admission = iota // 立即开始
+	admitQueue                  // 排队等待同一任务之前的执行结束
+	admitSkip                   // 同一任务已有执行，跳过本次执行
+)
+
+// RunRegistry 记录已提交和正在执行中的任务及其取消函数，用于并发控制、查询和手动取消
+type RunRegistry struct {
+	mu      sync.Mutex
+	runs    map[string]*runningRun     // runID -> 执行中
+	pending map[string]*runningRun     // runID -> 已提交但尚未开始
+	tasks   map[string]*taskExecutions // taskID -> 已开始和排队中的执行
+}
+
+func NewRunRegistry() *RunRegistry {
+	return &RunRegistry{
+		runs:    make(map[string]*runningRun),
+		pending: make(map[string]*runningRun),
+		tasks:   make(map[string]*taskExecutions),
+	}
+}
+
+// admit 按任务的并发策略决定执行立即开始、排队还是跳过
+// replace 策略取消同一任务已开始的执行，本次执行排在它们之后，之前排队的执行被替换掉并返回给调用方结束
+func (r *RunRegistry) admit(e *execution) (admission, []*execution) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	te, ok := r.tasks[e.task.GetID()]
+	if !ok {
+		te = &taskExecutions{active: make(map[*execution]struct{})}
+		r.tasks[e.task.GetID()] = te
+	}
+	if len(te.active) > 0 {
+		switch e.task.GetConcurrencyPolicy() {
+		case ConcurrencyForbid:
+			return admitSkip, nil
+		case ConcurrencyQueue:
+			te.queue = append(te.queue, e)
+			return admitQueue, nil
+		case ConcurrencyReplace:
+			for active := range te.active {
+				active.cancel()
+			}
+			replaced := te.queue
+			te.queue = []*execution{e}
+			return admitQueue, replaced
+		}
+	}
+	te.active[e] = struct{}{}
+	return admitStart, nil
+}
+
+// release 执行结束后调用，同一任务已没有执行中的执行时取出队列中的下一个执行并登记为已开始
+func (r *RunRegistry) release(e *execution) *execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	te, ok := r.tasks[e.task.GetID()]
+	if !ok {
+		return nil
+	}
+	if _, ok := te.active[e]; !ok {
+		return nil
+	}
+	delete(te.active, e)
+	if len(te.active) > 0 {
+		return nil
+	}
+	if len(te.queue) == 0 {
+		delete(r.tasks, e.task.GetID())
+		return nil
+	}
+	next := te.queue[0]
+	te.queue[0] = nil
+	te.queue = te.queue[1:]
+	te.active[next] = struct{}{}
+	return next
+}
+
+// dequeue 从队列中移除尚未开始的执行，执行已不在队列中时返回 false
+func (r *RunRegistry) dequeue(e *execution) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	te, ok := r.tasks[e.task.GetID()]
+	if !ok {
+		return false
+	}
+	for i, queued := range te.queue {
+		if queued == e {
+			te.queue = append(te.queue[:i], te.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// addPending 登记一次已提交但尚未开始的执行，cancel 用于在开始前取消它
+func (r *RunRegistry) addPending(t Task, rs *RunState, cancel context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending[rs.RunID] = &runningRun{task: t, state: rs, cancel: cancel}
+}
+
+// removePending 移除仍处于等待状态的执行，已开始的执行不受影响
+func (r *RunRegistry) removePending(runID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, runID)
+}
+
+// runIDs 返回所有等待中和执行中的运行ID
+func (r *RunRegistry) runIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(r.pending)+len(r.runs))
+	for id := range r.pending {
+		ids = append(ids, id)
+	}
+	for id := range r.runs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// start 把一次尝试从等待中转为执行中，返回尝试结束时调用的注销函数
+// cancel 取消该尝试所属的整个执行，取消后不再重试
+func (r *RunRegistry) start(t Task, rs *RunState, cancel context.CancelFunc) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.pending, rs.RunID)
+	r.runs[rs.RunID] = &runningRun{task: t, state: rs, cancel: cancel}
+	return func() {
+		r.mu.Lock()
+		delete(r.runs, rs.RunID)
+		r.mu.Unlock()
+		rs.closeOutput()
+	}
+}
+
+// list 返回用户正在执行中的任务，按开始时间排序
+func (r *RunRegistry) list(ownerName string) []RunningInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]RunningInfo, 0)
+	for _, run := range r.pending {
+		if run.task.GetOwnerName() == ownerName {
+			res = append(res, run.info(RunningStatusPending))
+		}
+	}
+	for _, run := range r.runs {
+		if run.task.GetOwnerName() == ownerName {
+			res = append(res, run.info(RunningStatusRunning))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].StartTime.Before(res[j].StartTime)
+	})
+	return res
+}
+
+// get 查询用户的某次等待中或执行中的运行
+func (r *RunRegistry) get(ownerName, runID string) (RunningInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if run, ok := r.runs[runID]; ok && run.task.GetOwnerName() == ownerName {
+		info := run.info(RunningStatusRunning)
+		info.Output, info.ErrOutput = run.state.RecentOutput()
+		return info, true
+	}
+	if run, ok := r.pending[runID]; ok && run.task.GetOwnerName() == ownerName {
+		return run.info(RunningStatusPending), true
+	}
+	return RunningInfo{}, false
+}
+
+// subscribe 订阅用户某次等待中或执行中的运行的实时输出
+func (r *RunRegistry) subscribe(ownerName, runID string) (<-chan OutputLine, func(), bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run, ok := r.runs[runID]
+	if !ok {
+		run, ok = r.pending[runID]
+	}
+	if !ok || run.task.GetOwnerName() != ownerName {
+		return nil, nil, false
+	}
+	lines, unsubscribe := run.state.SubscribeOutput()
+	return lines, unsubscribe, true
+}
+
+// cancel 取消用户的某次等待中或执行中的运行
+func (r *RunRegistry) cancel(ownerName, runID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run, ok := r.runs[runID]
+	if !ok {
+		run, ok = r.pending[runID]
+	}
+	if !ok || run.task.GetOwnerName() != ownerName {
+		return fmt.Errorf("running run id %s not found", runID)
+	}
+	run.cancel()
+	return nil
+}
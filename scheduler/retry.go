@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+const (
+	BackoffFixed       = "fixed"       // 固定间隔
+	BackoffExponential = "exponential" // 指数退避
+)
+
+// RetryPolicy 任务失败后的重试策略
+type RetryPolicy struct {
+	MaxAttempts      int           `json:"max_attempts"`        // 最大尝试次数(含首次执行)，<=1 表示不重试
+	Backoff          string        `json:"backoff"`             // 退避方式 fixed/exponential
+	Interval         time.Duration `json:"interval"`            // 首次重试前的等待时间，持久化时以纳秒保存
+	MaxInterval      time.Duration `json:"max_interval"`        // 指数退避的最大等待时间，0 表示不限制，持久化时以纳秒保存
+	RetryOnExitCodes []int         `json:"retry_on_exit_codes"` // 仅在这些退出码时重试
+	RetryOnTimeout   bool          `json:"retry_on_timeout"`    // 超时时重试
+}
+
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry max_attempts must be at least 1")
+	}
+	if p.Backoff != "" && p.Backoff != BackoffFixed && p.Backoff != BackoffExponential {
+		return fmt.Errorf("retry backoff %q unknown", p.Backoff)
+	}
+	if p.Interval < 0 || p.MaxInterval < 0 {
+		return fmt.Errorf("retry interval cannot be negative")
+	}
+	return nil
+}
+
+// ShouldRetry 判断本次执行结果是否需要重试
+// 未配置退出码和超时条件时，失败、超时、panic 均会重试；被取消、跳过、超出资源限制或因服务停止被终止的执行不会重试
+func (p *RetryPolicy) ShouldRetry(result TaskResult) bool {
+	switch result.Status {
+	case model.RunStatusSucceeded, model.RunStatusCancelled, model.RunStatusRunning, model.RunStatusSkipped,
+		model.RunStatusLimitExceeded, model.RunStatusInterrupted:
+		return false
+	}
+
+	if len(p.RetryOnExitCodes) == 0 && !p.RetryOnTimeout {
+		return true
+	}
+
+	if result.Status == model.RunStatusTimedOut {
+		return p.RetryOnTimeout
+	}
+	return result.Status == model.RunStatusFailed && slices.Contains(p.RetryOnExitCodes, result.ExitCode)
+}
+
+// Delay 返回第 retry 次重试(从1开始)前的等待时间
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	if p.Backoff != BackoffExponential || retry <= 1 {
+		return p.Interval
+	}
+
+	delay := p.Interval
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if p.MaxInterval > 0 && delay >= p.MaxInterval {
+			return p.MaxInterval
+		}
+	}
+	return delay
+}
+
+func (p *RetryPolicy) ToJson() string {
+	if p == nil {
+		return ""
+	}
+	res, _ := json.Marshal(p)
+	return string(res)
+}
+
+func retryPolicyFromJson(jsonStr string) (*RetryPolicy, error) {
+	if jsonStr == "" {
+		return nil, nil
+	}
+	p := new(RetryPolicy)
+	if err := json.Unmarshal([]byte(jsonStr), p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	fixed := &RetryPolicy{Backoff: BackoffFixed, Interval: time.Second}
+	assert.Equal(t, time.Second, fixed.Delay(1))
+	assert.Equal(t, time.Second, fixed.Delay(3))
+
+	exp := &RetryPolicy{Backoff: BackoffExponential, Interval: time.Second, MaxInterval: 5 * time.Second}
+	assert.Equal(t, time.Second, exp.Delay(1))
+	assert.Equal(t, 2*time.Second, exp.Delay(2))
+	assert.Equal(t, 4*time.Second, exp.Delay(3))
+	assert.Equal(t, 5*time.Second, exp.Delay(4))
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	anyFailure := &RetryPolicy{MaxAttempts: 3}
+	assert.True(t, anyFailure.ShouldRetry(TaskResult{Status: model.RunStatusFailed, ExitCode: 2}))
+	assert.True(t, anyFailure.ShouldRetry(TaskResult{Status: model.RunStatusTimedOut}))
+	assert.False(t, anyFailure.ShouldRetry(TaskResult{Status: model.RunStatusSucceeded}))
+	assert.False(t, anyFailure.ShouldRetry(TaskResult{Status: model.RunStatusCancelled}))
+
+	filtered := &RetryPolicy{MaxAttempts: 3, RetryOnExitCodes: []int{75}}
+	assert.True(t, filtered.ShouldRetry(TaskResult{Status: model.RunStatusFailed, ExitCode: 75}))
+	assert.False(t, filtered.ShouldRetry(TaskResult{Status: model.RunStatusFailed, ExitCode: 1}))
+	assert.False(t, filtered.ShouldRetry(TaskResult{Status: model.RunStatusTimedOut}))
+
+	timeoutOnly := &RetryPolicy{MaxAttempts: 3, RetryOnTimeout: true}
+	assert.True(t, timeoutOnly.ShouldRetry(TaskResult{Status: model.RunStatusTimedOut}))
+	assert.False(t, timeoutOnly.ShouldRetry(TaskResult{Status: model.RunStatusFailed, ExitCode: 1}))
+}
+
+func TestCronScheduler_Retry(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	defer s.Stop()
+
+	task := NewTask("task_1", "retry", "tester", "* * * * *", "",
+		NewShellJob(true, 5*time.Second, t.TempDir(), "tester", "exit 1"),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: BackoffFixed, Interval: 50 * time.Millisecond}))
+	parent, err := s.RunTask(task)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(store.savedRuns()) == 3 && s.Health().Running == 0
+	}, 5*time.Second, 20*time.Millisecond)
+	attempts := make(map[int]model.TaskLog)
+	for _, runID := range store.savedRuns() {
+		l, _ := store.find(runID)
+		assert.Equal(t, model.RunStatusFailed, l.Status)
+		attempts[l.Attempt] = l
+	}
+	assert.Equal(t, parent, attempts[1].RunId)
+	for _, attempt := range []int{2, 3} {
+		assert.Equal(t, parent, attempts[attempt].ParentRunId)
+		assert.Equal(t, model.TriggerManual, attempts[attempt].Trigger)
+	}
+}
+
+func TestCronScheduler_CancelPendingRetry(t *testing.T) {
+	store := &fakeTaskLogStore{}
+	s := newTestCronScheduler(t, store, time.Second)
+	defer s.Stop()
+
+	task := NewTask("task_1", "retry", "tester", "* * * * *", "",
+		NewShellJob(true, 5*time.Second, t.TempDir(), "tester", "exit 1"),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, Backoff: BackoffFixed, Interval: time.Hour}))
+	_, err := s.RunTask(task)
+	assert.NoError(t, err)
+
+	// 等待重试期间下一次尝试处于 pending 状态，不占用协程池，可以被取消
+	var next RunningInfo
+	assert.Eventually(t, func() bool {
+		runs := s.ListRunning("tester")
+		if len(runs) != 1 || runs[0].Attempt != 2 {
+			return false
+		}
+		next = runs[0]
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, RunningStatusPending, next.Status)
+	assert.Eventually(t, func() bool { return s.pool.Running() == 0 }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, s.CancelRun("tester", next.RunID))
+	assert.Eventually(t, func() bool { return s.Health().Running == 0 }, time.Second, 10*time.Millisecond)
+	assert.Empty(t, s.ListRunning("tester"))
+}
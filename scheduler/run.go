@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+const (
+	RunIDPrefix = "run_"
+)
+
+type runStateKey struct{}
+
+// RunState 单次执行的运行时状态，随 context 在执行链和 Job 之间传递
+type RunState struct {
+	RunID     string
+	TaskID    string
+	Trigger   string // 触发来源，见 model.TriggerCron 等
+	StartTime time.Time
+
+	ParentRunID   string // 重试时指向首次执行的运行ID，恢复执行时指向被中断的运行ID
+	Attempt       int    // 第几次尝试，从1开始
+	WorkflowRunID string // 所属工作流运行ID，不属于工作流时为空
+
+	mu       sync.Mutex
+	status   string
+	exitCode int
+	pid      int
+	usage    model.ResourceUsage // 本次执行启动的各进程累计的资源使用
+	secrets  []string            // 本次执行用到的密钥值，输出中需要打码
+
+	spool *outputSpool // 输出超过上限时保存完整输出，在 Job 开始执行前设置
+
+	output *outputBroadcaster
+}
+
+func NewRunState(runID, taskID, trigger string) *RunState {
+	return &RunState{
+		RunID:     runID,
+		TaskID:    taskID,
+		Trigger:   trigger,
+		StartTime: time.Now(),
+		Attempt:   1,
+		exitCode:  -1,
+		output:    newOutputBroadcaster(),
+	}
+}
+
+// WithRunState 将运行状态写入 context
+func WithRunState(ctx context.Context, rs *RunState) context.Context {
+	return context.WithValue(ctx, runStateKey{}, rs)
+}
+
+// RunStateFromContext 从 context 中取出运行状态
+func RunStateFromContext(ctx context.Context) (*RunState, bool) {
+	rs, ok := ctx.Value(runStateKey{}).(*RunState)
+	return rs, ok && rs != nil
+}
+
+// ensureRunState 保证 context 中存在运行状态，直接调用执行器时补充一个手动触发的状态
+func ensureRunState(ctx context.Context, t Task) (context.Context, *RunState) {
+	if rs, ok := RunStateFromContext(ctx); ok {
+		return ctx, rs
+	}
+	rs := NewRunState("", t.id, model.TriggerManual)
+	return WithRunState(ctx, rs), rs
+}
+
+// SetStatus 由 Job 上报执行结果状态，未上报时由执行器根据输出推断
+func (r *RunState) SetStatus(status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *RunState) Status() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+func (r *RunState) SetExitCode(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exitCode = code
+}
+
+func (r *RunState) ExitCode() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exitCode
+}
+
+// SetPID 由进程类 Job 在进程启动后上报进程ID
+func (r *RunState) SetPID(pid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pid = pid
+}
+
+func (r *RunState) PID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pid
+}
+
+// addUsage 由进程类 Job 在每个进程结束后累加资源使用统计
+func (r *RunState) addUsage(u model.ResourceUsage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usage.Add(u)
+}
+
+func (r *RunState) Usage() model.ResourceUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.usage
+}
+
+// addSecretValue 登记本次执行用到的密钥值
+func (r *RunState) addSecretValue(value string) {
+	if value == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = append(r.secrets, value)
+	// 先替换较长的值，避免一个密钥是另一个密钥的子串时只打码了一部分
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+}
+
+// MaskSecrets 将文本中出现的密钥值替换为 SecretMask
+func (r *RunState) MaskSecrets(s string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.secrets {
+		s = strings.ReplaceAll(s, v, SecretMask)
+	}
+	return s
+}
+
+// PublishOutput 由 Job 在执行过程中逐行上报输出，推送给实时订阅者
+func (r *RunState) PublishOutput(stream, line string) {
+	line = r.MaskSecrets(line)
+	r.output.publish(OutputLine{Stream: stream, Line: line, Time: time.Now()})
+	if r.spool != nil {
+		r.spool.writeLine(stream, line)
+	}
+}
+
+// finishSpool 结束某一路输出的保存，返回完整输出的文件名，输出未超过上限或未配置输出目录时返回空
+func (r *RunState) finishSpool(stream, output string) string {
+	if r.spool == nil {
+		return ""
+	}
+	name, _ := r.spool.finish(stream, output)
+	return name
+}
+
+// SubscribeOutput 订阅本次执行的实时输出，执行结束后通道会被关闭
+func (r *RunState) SubscribeOutput() (<-chan OutputLine, func()) {
+	return r.output.subscribe()
+}
+
+// RecentOutput 返回本次执行保留的最近输出，标准输出和错误输出分别按行拼接
+func (r *RunState) RecentOutput() (stdout, stderr string) {
+	return r.output.recent()
+}
+
+// closeOutput 结束本次执行的输出推送
+func (r *RunState) closeOutput() {
+	r.output.close()
+}
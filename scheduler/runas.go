@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/pkg"
+)
+
+// RunAs 任务进程使用的系统用户
+type RunAs struct {
+	UID uint32
+	GID uint32
+}
+
+// RunAsMapper 将 GoDo 用户映射到执行任务的系统用户
+type RunAsMapper struct {
+	users    map[string]RunAs
+	fallback *RunAs // 未单独映射的用户共用的沙箱用户
+}
+
+func NewRunAsMapper(conf *config.ScheduleConfig) (*RunAsMapper, error) {
+	m := &RunAsMapper{users: make(map[string]RunAs)}
+	if conf.RunAs == nil {
+		return m, nil
+	}
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("run_as is not supported on windows")
+	}
+
+	if conf.RunAs.Default != nil {
+		ra, err := resolveOSUser(*conf.RunAs.Default)
+		if err != nil {
+			return nil, fmt.Errorf("run_as default: %w", err)
+		}
+		m.fallback = &ra
+	}
+	for _, u := range conf.RunAs.Users {
+		if u.Name == "" {
+			return nil, fmt.Errorf("run_as users: name is required")
+		}
+		if _, ok := m.users[u.Name]; ok {
+			return nil, fmt.Errorf("run_as users: duplicate user %s", u.Name)
+		}
+		ra, err := resolveOSUser(u)
+		if err != nil {
+			return nil, fmt.Errorf("run_as user %s: %w", u.Name, err)
+		}
+		m.users[u.Name] = ra
+	}
+	return m, nil
+}
+
+// resolveOSUser 设置了系统用户名时从系统中查询 uid/gid，不允许映射到 root
+func resolveOSUser(conf config.OSUserConfig) (RunAs, error) {
+	ra := RunAs{UID: conf.UID, GID: conf.GID}
+	if conf.OSUser != "" {
+		u, err := user.Lookup(conf.OSUser)
+		if err != nil {
+			return RunAs{}, err
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return RunAs{}, fmt.Errorf("invalid uid %s of %s", u.Uid, conf.OSUser)
+		}
+		gid, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return RunAs{}, fmt.Errorf("invalid gid %s of %s", u.Gid, conf.OSUser)
+		}
+		ra = RunAs{UID: uint32(uid), GID: uint32(gid)}
+	}
+	if ra.UID == 0 || ra.GID == 0 {
+		return RunAs{}, fmt.Errorf("refuse to run tasks as root")
+	}
+	return ra, nil
+}
+
+// Lookup 返回用户映射的系统用户，没有映射时任务以服务自身的用户运行
+func (m *RunAsMapper) Lookup(userName string) (RunAs, bool) {
+	if ra, ok := m.users[userName]; ok {
+		return ra, true
+	}
+	if m.fallback != nil {
+		return *m.fallback, true
+	}
+	return RunAs{}, false
+}
+
+// PrepareDir 创建用户的工作目录，用户映射了系统用户时将目录交给该用户并禁止其他用户访问
+func (m *RunAsMapper) PrepareDir(userName, dir string) error {
+	ra, ok := m.Lookup(userName)
+	return prepareDir(dir, ra, ok)
+}
+
+// Chown 将用户上传的文件交给其映射的系统用户
+func (m *RunAsMapper) Chown(userName, path string) error {
+	ra, ok := m.Lookup(userName)
+	if !ok {
+		return nil
+	}
+	return os.Chown(path, int(ra.UID), int(ra.GID))
+}
+
+func prepareDir(dir string, ra RunAs, ok bool) error {
+	if err := pkg.CreateDirIfNotExist(dir); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if err := os.Chown(dir, int(ra.UID), int(ra.GID)); err != nil {
+		return err
+	}
+	return os.Chmod(dir, 0o700)
+}
+
+type runAsKey struct{}
+
+func withRunAs(ctx context.Context, ra RunAs) context.Context {
+	return context.WithValue(ctx, runAsKey{}, ra)
+}
+
+func runAsFromContext(ctx context.Context) (RunAs, bool) {
+	ra, ok := ctx.Value(runAsKey{}).(RunAs)
+	return ra, ok
+}
+
+// prepareWorkDir 创建任务的工作目录，并交给本次执行使用的系统用户
+func prepareWorkDir(ctx context.Context, dir string) error {
+	ra, ok := runAsFromContext(ctx)
+	return prepareDir(dir, ra, ok)
+}
+
+// RunAsMiddleware 将任务所属用户映射的系统用户写入 context，由启动进程的 Job 在进程启动时切换
+type RunAsMiddleware struct {
+	mapper *RunAsMapper
+}
+
+func NewRunAsMiddleware(mapper *RunAsMapper) *RunAsMiddleware {
+	return &RunAsMiddleware{mapper: mapper}
+}
+
+func (rm *RunAsMiddleware) Handler(next Executor) Executor {
+	return func(ctx context.Context, t Task) TaskResult {
+		if ra, ok := rm.mapper.Lookup(t.ownerName); ok {
+			ctx = withRunAs(ctx, ra)
+		}
+		return next(ctx, t)
+	}
+}
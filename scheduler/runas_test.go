@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAsMapper_Lookup(t *testing.T) {
+	m, err := NewRunAsMapper(&config.ScheduleConfig{})
+	assert.NoError(t, err)
+	_, ok := m.Lookup("alice")
+	assert.False(t, ok)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("run_as is not supported on windows")
+	}
+	m, err = NewRunAsMapper(&config.ScheduleConfig{RunAs: &config.RunAsConfig{
+		Default: &config.OSUserConfig{UID: 65534, GID: 65534},
+		Users:   []config.OSUserConfig{{Name: "alice", UID: 2001, GID: 2001}},
+	}})
+	assert.NoError(t, err)
+	ra, ok := m.Lookup("alice")
+	assert.True(t, ok)
+	assert.Equal(t, RunAs{UID: 2001, GID: 2001}, ra)
+	ra, ok = m.Lookup("bob")
+	assert.True(t, ok)
+	assert.Equal(t, RunAs{UID: 65534, GID: 65534}, ra)
+
+	_, err = NewRunAsMapper(&config.ScheduleConfig{RunAs: &config.RunAsConfig{
+		Users: []config.OSUserConfig{{Name: "alice"}},
+	}})
+	assert.Error(t, err, "mapping to root should be rejected")
+}
+
+func TestShellJob_RunAs(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("switching users requires root")
+	}
+	// 工作目录的上级目录需要允许其他用户进入
+	dir := t.TempDir()
+	assert.NoError(t, os.Chmod(filepath.Dir(dir), 0o755))
+	assert.NoError(t, os.Chmod(dir, 0o755))
+	s := NewShellJob(true, 5*time.Second, dir, "tester", "id -u; id -G; touch out.txt")
+	rs := NewRunState("run_test", "task_test", model.TriggerManual)
+	ctx := withRunAs(WithRunState(context.Background(), rs), RunAs{UID: 65534, GID: 65534})
+
+	s.Run(ctx)
+	assert.Equal(t, model.RunStatusSucceeded, rs.Status(), readChannel(s.ErrOutput()))
+	assert.Equal(t, "65534\n65534", strings.TrimSpace(readChannel(s.Output())))
+
+	info, err := os.Stat(filepath.Join(dir, "tester"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+}
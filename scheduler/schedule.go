@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 任务的调度方式
+const (
+	ScheduleTypeCron  = "cron"  // cron 表达式
+	ScheduleTypeAt    = "at"    // 在指定时间执行一次，执行后任务自动结束
+	ScheduleTypeEvery = "every" // 按固定间隔执行
+)
+
+// at 调度支持的时间格式，未带时区偏移的按任务时区解析
+var atLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// AtSchedule 只在指定时间触发一次
+type AtSchedule struct {
+	At time.Time
+}
+
+// Next 指定时间之后返回零值，cron 将不再触发
+func (s AtSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.At) {
+		return s.At
+	}
+	return time.Time{}
+}
+
+// EverySchedule 按固定间隔触发，触发时间对齐到所在时区的 1970-01-01 00:00 加上偏移量，重启后触发时间保持不变
+type EverySchedule struct {
+	Interval time.Duration
+	Offset   time.Duration
+	Location *time.Location
+}
+
+func (s EverySchedule) Next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	base := time.Date(1970, 1, 1, 0, 0, 0, 0, loc).Add(s.Offset)
+	if t.Before(base) {
+		return base
+	}
+	n := t.Sub(base)/s.Interval + 1
+	return base.Add(n * s.Interval)
+}
+
+// parseSchedule 按调度方式和时区解析调度描述，cron 表达式使用传入的 parser
+// timeZone 为 IANA 时区名，为空时使用服务器本地时区；cron 表达式自带 CRON_TZ= 前缀时以前缀为准
+func parseSchedule(scheduleType, spec, timeZone string, parser cron.Parser) (cron.Schedule, error) {
+	loc, err := loadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scheduleType {
+	case "", ScheduleTypeCron:
+		if timeZone != "" && !hasTimeZonePrefix(spec) {
+			spec = fmt.Sprintf("CRON_TZ=%s %s", timeZone, spec)
+		}
+		return parser.Parse(spec)
+	case ScheduleTypeAt:
+		return parseAtSchedule(spec, loc)
+	case ScheduleTypeEvery:
+		return parseEverySchedule(spec, loc)
+	default:
+		return nil, fmt.Errorf("schedule type %q unknown", scheduleType)
+	}
+}
+
+// loadLocation 加载 IANA 时区，为空时返回服务器本地时区
+func loadLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %s", timeZone, err)
+	}
+	return loc, nil
+}
+
+func hasTimeZonePrefix(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	return strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=")
+}
+
+// parseAtSchedule 解析单次执行时间，如 "2026-11-01 03:00:00" 或 "2026-11-01T03:00:00+08:00"
+func parseAtSchedule(spec string, loc *time.Location) (AtSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	for _, layout := range atLayouts {
+		at, err := time.ParseInLocation(layout, spec, loc)
+		if err == nil {
+			return AtSchedule{At: at}, nil
+		}
+	}
+	return AtSchedule{}, fmt.Errorf("invalid time %q, expected format like \"2006-01-02 15:04:05\" or RFC3339", spec)
+}
+
+// parseEverySchedule 解析固定间隔，格式为 "<间隔>[+<偏移>]"，如 "90s"、"1h+15m"
+func parseEverySchedule(spec string, loc *time.Location) (EverySchedule, error) {
+	intervalStr, offsetStr, hasOffset := strings.Cut(strings.TrimSpace(spec), "+")
+
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return EverySchedule{}, fmt.Errorf("invalid interval %q: %s", intervalStr, err)
+	}
+	if interval < time.Second {
+		return EverySchedule{}, fmt.Errorf("interval must be at least 1s")
+	}
+
+	var offset time.Duration
+	if hasOffset {
+		offset, err = time.ParseDuration(offsetStr)
+		if err != nil {
+			return EverySchedule{}, fmt.Errorf("invalid offset %q: %s", offsetStr, err)
+		}
+		if offset < 0 || offset >= interval {
+			return EverySchedule{}, fmt.Errorf("offset must be in [0, interval)")
+		}
+	}
+	return EverySchedule{Interval: interval, Offset: offset, Location: loc}, nil
+}
+
+// ValidateSchedule 校验调度描述和时区，cron 表达式的秒字段可选，实际解析以调度器配置为准
+// 创建或修改任务时调用，at 调度的时间必须晚于当前时间，否则任务永远不会触发也不会结束
+func ValidateSchedule(scheduleType, spec, timeZone string) error {
+	parser := cron.NewParser(
+		cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+	)
+	sche, err := parseSchedule(scheduleType, spec, timeZone, parser)
+	if err != nil {
+		return err
+	}
+	if at, ok := sche.(AtSchedule); ok && !at.At.After(time.Now()) {
+		return fmt.Errorf("time %q is not in the future", spec)
+	}
+	return nil
+}
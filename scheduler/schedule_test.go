@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtSchedule_Next(t *testing.T) {
+	at := time.Date(2026, 11, 1, 3, 0, 0, 0, time.UTC)
+	s := AtSchedule{At: at}
+
+	assert.Equal(t, at, s.Next(at.Add(-time.Hour)))
+	assert.True(t, s.Next(at).IsZero())
+	assert.True(t, s.Next(at.Add(time.Second)).IsZero())
+}
+
+func TestEverySchedule_Next(t *testing.T) {
+	s := EverySchedule{Interval: time.Hour, Offset: 15 * time.Minute}
+	now := time.Date(2026, 11, 1, 3, 20, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 11, 1, 4, 15, 0, 0, time.UTC), s.Next(now).UTC())
+	assert.Equal(t, time.Date(2026, 11, 1, 4, 15, 0, 0, time.UTC), s.Next(time.Date(2026, 11, 1, 3, 15, 0, 0, time.UTC)).UTC())
+
+	s = EverySchedule{Interval: 90 * time.Second}
+	next := s.Next(now)
+	assert.True(t, next.After(now))
+	assert.LessOrEqual(t, next.Sub(now), 90*time.Second)
+	assert.Zero(t, next.Unix()%90)
+}
+
+func TestParseSchedule(t *testing.T) {
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	tests := []struct {
+		scheduleType string
+		spec         string
+		timeZone     string
+		wantErr      bool
+	}{
+		{ScheduleTypeCron, "0 0 2 * * *", "", false},
+		{"", "0 0 2 * * *", "", false},
+		{ScheduleTypeCron, "not cron", "", true},
+		{ScheduleTypeCron, "0 0 9 * * 1-5", "America/New_York", false},
+		{ScheduleTypeCron, "CRON_TZ=Europe/London 0 0 9 * * *", "Asia/Tokyo", false},
+		{ScheduleTypeCron, "0 0 9 * * *", "Mars/Olympus", true},
+		{ScheduleTypeAt, "2026-11-01 03:00:00", "", false},
+		{ScheduleTypeAt, "2026-11-01T03:00:00+08:00", "", false},
+		{ScheduleTypeAt, "tomorrow", "", true},
+		{ScheduleTypeEvery, "90s", "", false},
+		{ScheduleTypeEvery, "1h+15m", "", false},
+		{ScheduleTypeEvery, "500ms", "", true},
+		{ScheduleTypeEvery, "1h+2h", "", true},
+		{"weekly", "* * * * *", "", true},
+	}
+	for _, tt := range tests {
+		_, err := parseSchedule(tt.scheduleType, tt.spec, tt.timeZone, parser)
+		if tt.wantErr {
+			assert.Error(t, err, "%s %q", tt.scheduleType, tt.spec)
+		} else {
+			assert.NoError(t, err, "%s %q", tt.scheduleType, tt.spec)
+		}
+	}
+}
+
+func TestValidateSchedule_AtInPast(t *testing.T) {
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	assert.NoError(t, ValidateSchedule(ScheduleTypeAt, future, ""))
+	assert.Error(t, ValidateSchedule(ScheduleTypeAt, past, ""))
+	assert.NoError(t, ValidateSchedule(ScheduleTypeCron, "0 0 2 * * *", ""))
+}
+
+func TestParseSchedule_TimeZone(t *testing.T) {
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	ny, _ := time.LoadLocation("America/New_York")
+	now := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	sche, err := parseSchedule(ScheduleTypeCron, "0 0 9 * * *", "America/New_York", parser)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 2, 9, 0, 0, 0, ny), sche.Next(now).In(ny))
+
+	sche, err = parseSchedule(ScheduleTypeAt, "2026-11-02 09:00:00", "America/New_York", parser)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 2, 9, 0, 0, 0, ny), sche.Next(now).In(ny))
+
+	sche, err = parseSchedule(ScheduleTypeEvery, "24h+9h", "America/New_York", parser)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, sche.Next(now).In(ny).Hour())
+}
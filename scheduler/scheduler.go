@@ -1,22 +1,35 @@
 package scheduler
 
 import (
-	"context"
+	"github.com/chencheng8888/GoDo/dao"
+	"github.com/chencheng8888/GoDo/dao/model"
 	"github.com/google/wire"
+	"time"
 )
 
 var (
-	ProviderSet = wire.NewSet(NewCronScheduler, NewLogMiddleware, NewTaskLogMiddleware, NewScheduler)
+	ProviderSet = wire.NewSet(NewCronScheduler, NewLogMiddleware, NewTaskLogMiddleware, NewTaskLogWriter, wire.Bind(new(TaskLogStore), new(*dao.TaskLogDao)), NewSecretMiddleware, NewLimitMiddleware, NewRunAsMapper, NewRunAsMiddleware, NewRunRegistry, NewScheduler)
 )
 
 type Scheduler interface {
 	AddTask(t Task) error
+	UpdateTask(t Task) error
 	ListTasks(userName string) []Task
 	RemoveTask(userName string, taskId string) error
+	PauseTask(userName string, taskId string) error
+	ResumeTask(userName string, taskId string) error
 	Start()
 	Stop()
 	InitializeTasks()
-	RunTask(ctx context.Context, task Task)
+	RunTask(task Task) (string, error)
+	FireTimes(t Task) (next time.Time, prev time.Time)
+	PreviewSchedule(scheduleType, spec, timeZone string, count int) ([]time.Time, ScheduleDescription, error)
+	ListRunning(userName string) []RunningInfo
+	GetRunning(userName string, runId string) (RunningInfo, bool)
+	GetUnsavedLog(userName string, runId string) (model.TaskLog, bool)
+	SubscribeOutput(userName string, runId string) (<-chan OutputLine, func(), bool)
+	CancelRun(userName string, runId string) error
+	Health() HealthInfo
 }
 
 func NewScheduler(cronScheduler *CronScheduler) Scheduler {
@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/chencheng8888/GoDo/secret"
+	"go.uber.org/zap"
+)
+
+const (
+	// SecretMask 输出中替换密钥值的文本
+	SecretMask = "******"
+)
+
+// secretPlaceholder 在命令、参数、环境变量、请求地址/请求头/请求体中通过 ${secret:NAME} 引用用户密钥
+var secretPlaceholder = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SecretConsumer 引用了用户密钥的 Job，执行前由 SecretMiddleware 解析所需的密钥
+type SecretConsumer interface {
+	SecretRefs() []string
+}
+
+// secretRefs 返回文本中引用的密钥名称，去重并排序
+func secretRefs(values ...string) []string {
+	var refs []string
+	for _, v := range values {
+		for _, m := range secretPlaceholder.FindAllStringSubmatch(v, -1) {
+			if !slices.Contains(refs, m[1]) {
+				refs = append(refs, m[1])
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// envValues 返回环境变量的值，用于查找其中引用的密钥
+func envValues(env map[string]string) []string {
+	values := make([]string, 0, len(env))
+	for _, v := range env {
+		values = append(values, v)
+	}
+	return values
+}
+
+// validateEnv 校验环境变量名
+func validateEnv(env map[string]string) error {
+	for k := range env {
+		if !envNameRegexp.MatchString(k) {
+			return fmt.Errorf("invalid env name %q", k)
+		}
+	}
+	return nil
+}
+
+type secretsKey struct{}
+
+// WithSecrets 将本次执行解密后的密钥写入 context
+func WithSecrets(ctx context.Context, secrets map[string]string) context.Context {
+	return context.WithValue(ctx, secretsKey{}, secrets)
+}
+
+func secretsFromContext(ctx context.Context) map[string]string {
+	secrets, _ := ctx.Value(secretsKey{}).(map[string]string)
+	return secrets
+}
+
+// expandSecrets 将文本中的 ${secret:NAME} 替换为 context 中的密钥值
+func expandSecrets(ctx context.Context, s string) (string, error) {
+	if !strings.Contains(s, "${secret:") {
+		return s, nil
+	}
+	secrets := secretsFromContext(ctx)
+	var missing []string
+	res := secretPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := secretPlaceholder.FindStringSubmatch(m)[1]
+		value, ok := secrets[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("secret %s not resolved", strings.Join(missing, ","))
+	}
+	return res, nil
+}
+
+// expandAllSecrets 依次替换多个文本中的密钥引用
+func expandAllSecrets(ctx context.Context, values []string) ([]string, error) {
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		expanded, err := expandSecrets(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, expanded)
+	}
+	return res, nil
+}
+
+// baseEnvKeys 子进程从服务进程继承的环境变量，其余变量(如数据库、JWT 配置)不会传给任务
+var baseEnvKeys = []string{"PATH", "HOME", "USER", "LANG", "LC_ALL", "TZ", "TMPDIR", "SHELL"}
+
+// windowsEnvKeys Windows 下进程正常运行所需的环境变量
+var windowsEnvKeys = []string{"SystemRoot", "ComSpec", "PATHEXT", "TEMP", "TMP", "USERPROFILE"}
+
+// buildEnv 构造子进程的环境变量：基础变量加上任务自定义的变量，变量值中的密钥引用在此时替换
+func buildEnv(ctx context.Context, env map[string]string) ([]string, error) {
+	inherited := baseEnvKeys
+	if runtime.GOOS == "windows" {
+		inherited = append(slices.Clone(baseEnvKeys), windowsEnvKeys...)
+	}
+
+	res := make([]string, 0, len(inherited)+len(env))
+	for _, k := range inherited {
+		if _, overridden := env[k]; overridden {
+			continue
+		}
+		if v, ok := os.LookupEnv(k); ok {
+			res = append(res, k+"="+v)
+		}
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, err := expandSecrets(ctx, env[k])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, k+"="+v)
+	}
+	return res, nil
+}
+
+// SecretResolver 解密用户的密钥
+type SecretResolver interface {
+	Resolve(userName string, names []string) (map[string]string, error)
+}
+
+// SecretMiddleware 在执行前解密任务引用的密钥写入 context，并登记到运行状态以便在输出中打码
+// 每次尝试都会经过该中间件，保证重试时也能拿到密钥
+type SecretMiddleware struct {
+	log      *zap.SugaredLogger
+	resolver SecretResolver
+}
+
+func NewSecretMiddleware(log *zap.SugaredLogger, store *secret.Store) *SecretMiddleware {
+	return &SecretMiddleware{log: log, resolver: store}
+}
+
+func (sm *SecretMiddleware) Handler(next Executor) Executor {
+	return func(ctx context.Context, t Task) TaskResult {
+		consumer, ok := t.f.(SecretConsumer)
+		if !ok {
+			return next(ctx, t)
+		}
+		refs := consumer.SecretRefs()
+		if len(refs) == 0 {
+			return next(ctx, t)
+		}
+
+		ctx, rs := ensureRunState(ctx, t)
+		secrets, err := sm.resolver.Resolve(t.ownerName, refs)
+		if err != nil {
+			sm.log.Errorf("resolve secrets %v for task %s failed: %v", refs, t.id, err)
+			return abortedResult(rs, model.RunStatusFailed, fmt.Sprintf("resolve secrets failed: %v", err))
+		}
+		for _, v := range secrets {
+			rs.addSecretValue(v)
+		}
+		return next(WithSecrets(ctx, secrets), t)
+	}
+}
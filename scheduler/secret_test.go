@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeSecretResolver map[string]string
+
+func (f fakeSecretResolver) Resolve(_ string, names []string) (map[string]string, error) {
+	res := make(map[string]string, len(names))
+	for _, name := range names {
+		v, ok := f[name]
+		if !ok {
+			return nil, fmt.Errorf("secret %s not found", name)
+		}
+		res[name] = v
+	}
+	return res, nil
+}
+
+func TestSecretRefsAndExpand(t *testing.T) {
+	job := NewShellJob(false, time.Second, "", "", "curl", "-u", "admin:${secret:api_token}", "${secret:host}")
+	job.Env = map[string]string{"DB_PASSWORD": "${secret:db_password}", "MODE": "prod"}
+	assert.Equal(t, []string{"api_token", "db_password", "host"}, job.SecretRefs())
+
+	ctx := WithSecrets(context.Background(), map[string]string{"api_token": "t0k3n"})
+	s, err := expandSecrets(ctx, "admin:${secret:api_token}")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin:t0k3n", s)
+
+	_, err = expandSecrets(ctx, "${secret:host}")
+	assert.Error(t, err)
+}
+
+func TestBuildEnv(t *testing.T) {
+	t.Setenv("GODO_SERVER_ONLY", "leaked")
+	ctx := WithSecrets(context.Background(), map[string]string{"db_password": "p@ss"})
+
+	env, err := buildEnv(ctx, map[string]string{"DB_PASSWORD": "${secret:db_password}", "HOME": "/tmp/home"})
+	assert.NoError(t, err)
+	assert.Contains(t, env, "DB_PASSWORD=p@ss")
+	assert.Contains(t, env, "HOME=/tmp/home")
+	assert.Contains(t, env, "PATH="+os.Getenv("PATH"))
+	assert.NotContains(t, env, "GODO_SERVER_ONLY=leaked")
+
+	assert.Error(t, validateEnv(map[string]string{"1BAD": "x"}))
+}
+
+func TestSecretMiddleware_MaskOutput(t *testing.T) {
+	dir := t.TempDir()
+	job := NewShellJob(true, 5*time.Second, dir, "tester", "echo", "token=${secret:api_token}", "db=$DB_PASSWORD")
+	job.Env = map[string]string{"DB_PASSWORD": "${secret:db_password}"}
+	task := NewTask("task_1", "secret", "tester", "* * * * *", "", job)
+
+	resolver := fakeSecretResolver{"api_token": "t0k3n", "db_password": "p@ss"}
+	sm := &SecretMiddleware{log: zap.NewNop().Sugar(), resolver: resolver}
+
+	rs := NewRunState("run_1", "task_1", model.TriggerManual)
+	lines, unsubscribe := rs.SubscribeOutput()
+	defer unsubscribe()
+
+	result := sm.Handler(BaseExecutor)(WithRunState(context.Background(), rs), task)
+	assert.Equal(t, model.RunStatusSucceeded, result.Status)
+	assert.Equal(t, "token=****** db=******\n", result.Output)
+	line := <-lines
+	assert.Equal(t, "token=****** db=******", line.Line)
+	assert.NotContains(t, job.Content(), "t0k3n")
+	assert.NotContains(t, job.ToJson(), "p@ss")
+
+	delete(resolver, "db_password")
+	result = sm.Handler(BaseExecutor)(context.Background(), task)
+	assert.Equal(t, model.RunStatusFailed, result.Status)
+	assert.Contains(t, result.ErrOutput, "db_password")
+}
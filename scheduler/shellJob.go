@@ -1,9 +1,9 @@
 package scheduler
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -11,7 +11,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/chencheng8888/GoDo/pkg"
+	"github.com/chencheng8888/GoDo/dao/model"
 )
 
 const (
@@ -19,12 +19,16 @@ const (
 )
 
 type ShellJob struct {
-	Command   string        `json:"command"`   // shell 命令
-	Args      []string      `json:"args"`      // 命令参数
-	UseShell  bool          `json:"use_shell"` //是否通过系统默认 Shell 执行 (true: 可以运行内建命令和脚本, false: 直接运行可执行文件)
-	Timeout   time.Duration `json:"timeout"`
-	output    chan string   // 标准输出
-	errOutput chan string   // 错误输出
+	Command  string        `json:"command"`   // shell 命令
+	Args     []string      `json:"args"`      // 命令参数
+	UseShell bool          `json:"use_shell"` //是否通过系统默认 Shell 执行 (true: 可以运行内建命令和脚本, false: 直接运行可执行文件)
+	Timeout  time.Duration `json:"timeout"`
+	// Env 任务自定义的环境变量，值中可以通过 ${secret:NAME} 引用用户密钥
+	Env map[string]string `json:"env,omitempty"`
+	// Limits 任务的资源限制，覆盖用户的默认限制但不能超过它
+	Limits    *model.ResourceLimits `json:"limits,omitempty"`
+	output    chan string           // 标准输出
+	errOutput chan string           // 错误输出
 
 	workDir string // 工作目录
 
@@ -37,10 +41,12 @@ func (s *ShellJob) Content() string {
 	}
 
 	type result struct {
-		Command  string   `json:"command"`   // shell 命令
-		Args     []string `json:"args"`      // 命令参数
-		UseShell bool     `json:"use_shell"` //是否通过系统默认 Shell 执行 (true: 可以运行内建命令和脚本, false: 直接运行可执行文件)
-		TimeOut  string   `json:"timeout"`
+		Command  string                `json:"command"`   // shell 命令
+		Args     []string              `json:"args"`      // 命令参数
+		UseShell bool                  `json:"use_shell"` //是否通过系统默认 Shell 执行 (true: 可以运行内建命令和脚本, false: 直接运行可执行文件)
+		TimeOut  string                `json:"timeout"`
+		Env      map[string]string     `json:"env,omitempty"` // 密钥以引用形式展示，不会包含明文
+		Limits   *model.ResourceLimits `json:"limits,omitempty"`
 	}
 
 	res := result{
@@ -48,6 +54,8 @@ func (s *ShellJob) Content() string {
 		Args:     s.Args,
 		UseShell: s.UseShell,
 		TimeOut:  s.Timeout.String(),
+		Env:      s.Env,
+		Limits:   s.Limits,
 	}
 
 	resStr, _ := json.Marshal(res)
@@ -71,45 +79,75 @@ func (s *ShellJob) Type() string {
 	return ShellJobType
 }
 
-func (s *ShellJob) Run(ctx context.Context) {
-	shellCtx, cancel := context.WithTimeout(ctx, s.Timeout)
-	defer cancel()
+const shellJobSchema = `{
+  "type": "object",
+  "required": ["command", "timeout"],
+  "additionalProperties": false,
+  "properties": {
+    "command": {"type": "string", "minLength": 1, "description": "执行命令"},
+    "args": {"type": "array", "items": {"type": "string"}, "description": "命令参数"},
+    "use_shell": {"type": "boolean", "description": "是否使用Shell"},
+    "timeout": {"type": "integer", "exclusiveMinimum": 0, "maximum": 7200, "description": "超时时间(秒)"},
+    "env": {"type": "object", "maxProperties": 50, "additionalProperties": {"type": "string"}, "description": "环境变量，值中可通过 ${secret:NAME} 引用密钥"},
+    "limits": ` + resourceLimitsSchema + `
+  }
+}`
+
+// shellJobSpec 创建Shell任务时 job 字段的结构
+type shellJobSpec struct {
+	Command  string                `json:"command" binding:"required"`
+	Args     []string              `json:"args"`
+	UseShell bool                  `json:"use_shell"`
+	Timeout  int                   `json:"timeout" binding:"required,max=7200,gt=0"`
+	Env      map[string]string     `json:"env" binding:"omitempty,max=50"`
+	Limits   *model.ResourceLimits `json:"limits"`
+}
 
-	var cmd *exec.Cmd
+func bindShellJob(bc JobBindContext, raw json.RawMessage) (Job, error) {
+	var spec shellJobSpec
+	if err := bindJobSpec(raw, &spec); err != nil {
+		return nil, err
+	}
+	if spec.UseShell && !bc.UseShell {
+		return nil, fmt.Errorf("the user is not allowed to use shell to run commands")
+	}
+	job := NewShellJob(spec.UseShell, time.Duration(spec.Timeout)*time.Second, bc.WorkDir, bc.UserName, spec.Command, spec.Args...)
+	job.Env = spec.Env
+	job.Limits = spec.Limits
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
 
-	if s.UseShell {
-		// --- 明确指定终端/Shell 解释器 ---
-		var shell string
-		var shellArgs []string
+// Validate 校验环境变量名
+func (s *ShellJob) Validate() error {
+	return validateEnv(s.Env)
+}
 
-		// 将 Command 和 Args 合并成一个完整的命令字符串
-		// 这样可以处理管道、重定向、Shell 变量等复杂的 Shell 语法
-		var fullCommand string
-		if len(s.Args) > 0 {
-			fullCommand = s.Command + " " + strings.Join(s.Args, " ")
-		} else {
-			fullCommand = s.Command
-		}
+func (s *ShellJob) GetLimits() *model.ResourceLimits {
+	return s.Limits
+}
 
-		if runtime.GOOS == "windows" {
-			// Windows: 使用 cmd.exe /C 执行命令
-			shell = "cmd"
-			shellArgs = []string{"/C", fullCommand}
-		} else {
-			// Linux/macOS: 使用 /bin/bash -c 执行命令
-			shell = "/bin/bash"
-			shellArgs = []string{"-c", fullCommand}
-		}
+// SecretRefs 返回命令、参数和环境变量中引用的密钥
+func (s *ShellJob) SecretRefs() []string {
+	values := append([]string{s.Command}, s.Args...)
+	return secretRefs(append(values, envValues(s.Env)...)...)
+}
 
-		cmd = exec.CommandContext(shellCtx, shell, shellArgs...)
-	} else {
-		// --- 直接运行可执行文件 (原有的方式) ---
-		cmd = exec.CommandContext(shellCtx, s.Command, s.Args...)
+func (s *ShellJob) Run(ctx context.Context) {
+	shellCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	cmd, err := newJobCommand(shellCtx, s.UseShell, s.Command, s.Args, s.Env)
+	if err != nil {
+		s.errOutput <- fmt.Sprintf("Command error: %v", err)
+		return
 	}
 
 	if len(s.workDir) > 0 && len(s.userName) > 0 {
 		dir := filepath.Join(s.workDir, s.userName)
-		err := pkg.CreateDirIfNotExist(dir)
+		err := prepareWorkDir(ctx, dir)
 		if err != nil {
 			s.errOutput <- "dir not found"
 			return
@@ -120,27 +158,149 @@ func (s *ShellJob) Run(ctx context.Context) {
 		return
 	}
 
-	var stdoutBuf, stderrBuf bytes.Buffer
-	cmd.Stdout = &stdoutBuf
-	cmd.Stderr = &stderrBuf
+	stdoutStr, stderrStr, err := runCommand(ctx, cmd)
 
-	err := cmd.Run()
-
-	stdoutStr := stdoutBuf.String()
-	stderrStr := stderrBuf.String()
+	reportRunResult(ctx, shellCtx, cmd, err)
 
 	// 只写入一次
 	if err != nil {
 		if stderrStr == "" {
 			stderrStr = err.Error()
 		}
-		s.errOutput <- fmt.Sprintf("Command error: %v\n%s", err, stderrStr)
+		s.errOutput <- fmt.Sprintf("%s\n%s", describeCommandError(shellCtx, s.Timeout, err), stderrStr)
 		return
 	}
 
 	s.output <- stdoutStr
 }
 
+// newCommand 构造要执行的命令，useShell 为 true 时通过系统默认 Shell 执行
+// 命令在独立的进程组中运行，ctx 结束时整个进程组会被终止，context 中有映射的系统用户时以该用户运行
+func newCommand(ctx context.Context, useShell bool, command string, args []string) *exec.Cmd {
+	cmd := newShellCommand(ctx, useShell, command, args)
+	setProcessGroup(cmd, killGracePeriodFromContext(ctx))
+	if ra, ok := runAsFromContext(ctx); ok {
+		setCredential(cmd, ra)
+	}
+	return cmd
+}
+
+func newShellCommand(ctx context.Context, useShell bool, command string, args []string) *exec.Cmd {
+	if !useShell {
+		// --- 直接运行可执行文件 (原有的方式) ---
+		return exec.CommandContext(ctx, command, args...)
+	}
+
+	// --- 明确指定终端/Shell 解释器 ---
+	// 将 Command 和 Args 合并成一个完整的命令字符串
+	// 这样可以处理管道、重定向、Shell 变量等复杂的 Shell 语法
+	fullCommand := command
+	if len(args) > 0 {
+		fullCommand = command + " " + strings.Join(args, " ")
+	}
+
+	if runtime.GOOS == "windows" {
+		// Windows: 使用 cmd.exe /C 执行命令
+		return exec.CommandContext(ctx, "cmd", "/C", fullCommand)
+	}
+	// Linux/macOS: 使用 /bin/bash -c 执行命令
+	return exec.CommandContext(ctx, "/bin/bash", "-c", fullCommand)
+}
+
+// newJobCommand 替换命令、参数中的密钥引用并设置子进程的环境变量
+func newJobCommand(ctx context.Context, useShell bool, command string, args []string, env map[string]string) (*exec.Cmd, error) {
+	expanded, err := expandAllSecrets(ctx, append([]string{command}, args...))
+	if err != nil {
+		return nil, err
+	}
+	cmd := newCommand(ctx, useShell, expanded[0], expanded[1:])
+	if cmd.Env, err = buildEnv(ctx, env); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// runCommand 启动命令并等待结束，输出完整保存的同时逐行推送给实时订阅者
+func runCommand(ctx context.Context, cmd *exec.Cmd) (string, string, error) {
+	rs, hasRunState := RunStateFromContext(ctx)
+	publish := func(stream string) func(line string) {
+		return func(line string) {
+			if hasRunState {
+				rs.PublishOutput(stream, line)
+			}
+		}
+	}
+
+	// 内存中只保留输出的开头和结尾，完整输出由 RunState 在超过上限时写入文件
+	limit := outputLimitsFromContext(ctx).limit
+	stdoutBuf, stderrBuf := newCappedBuffer(limit), newCappedBuffer(limit)
+	stdoutWriter := newLineWriter(stdoutBuf, publish(OutputStreamStdout))
+	stderrWriter := newLineWriter(stderrBuf, publish(OutputStreamStderr))
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+
+	limiter, err := newProcessLimiter(ctx, cmd)
+	if err != nil {
+		return "", "", err
+	}
+	defer limiter.cleanup()
+
+	err = cmd.Start()
+	if err == nil {
+		if hasRunState {
+			rs.SetPID(cmd.Process.Pid)
+		}
+		err = cmd.Wait()
+		err = limiter.check(cmd.ProcessState, err)
+		if hasRunState && cmd.ProcessState != nil {
+			rs.addUsage(processUsage(cmd.ProcessState))
+		}
+	}
+	stdoutWriter.Flush()
+	stderrWriter.Flush()
+
+	return stdoutBuf.String(), stderrBuf.String(), err
+}
+
+// describeCommandError 区分资源超限、超时、取消和命令自身的错误，超时和取消时进程组已被终止
+func describeCommandError(cmdCtx context.Context, timeout time.Duration, err error) string {
+	switch {
+	case errors.Is(err, ErrLimitExceeded):
+		return fmt.Sprintf("Command killed: %v", err)
+	case errors.Is(cmdCtx.Err(), context.DeadlineExceeded):
+		return fmt.Sprintf("Command timed out after %s", timeout)
+	case errors.Is(cmdCtx.Err(), context.Canceled):
+		return "Command cancelled"
+	default:
+		return fmt.Sprintf("Command error: %v", err)
+	}
+}
+
+// reportRunResult 将退出码和执行状态上报到 RunState
+func reportRunResult(ctx, shellCtx context.Context, cmd *exec.Cmd, err error) {
+	rs, ok := RunStateFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if cmd.ProcessState != nil {
+		rs.SetExitCode(cmd.ProcessState.ExitCode())
+	}
+
+	switch {
+	case err == nil:
+		rs.SetStatus(model.RunStatusSucceeded)
+	case errors.Is(err, ErrLimitExceeded):
+		rs.SetStatus(model.RunStatusLimitExceeded)
+	case errors.Is(shellCtx.Err(), context.DeadlineExceeded):
+		rs.SetStatus(model.RunStatusTimedOut)
+	case errors.Is(shellCtx.Err(), context.Canceled):
+		rs.SetStatus(model.RunStatusCancelled)
+	default:
+		rs.SetStatus(model.RunStatusFailed)
+	}
+}
+
 func (s *ShellJob) Output() <-chan string {
 	return s.output
 }
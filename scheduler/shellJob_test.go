@@ -2,7 +2,11 @@ package scheduler
 
 import (
 	"context"
+	"github.com/chencheng8888/GoDo/dao/model"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -21,6 +25,7 @@ func TestShellJob_Run(t *testing.T) {
 		name        string
 		fields      fields
 		shouldError bool
+		status      string
 	}{
 		{
 			name: "Test Echo Command",
@@ -33,6 +38,7 @@ func TestShellJob_Run(t *testing.T) {
 				errOutput: make(chan string, 100),
 			},
 			shouldError: false,
+			status:      model.RunStatusSucceeded,
 		},
 		{
 			name: "Test Go Command",
@@ -45,6 +51,7 @@ func TestShellJob_Run(t *testing.T) {
 				errOutput: make(chan string, 100),
 			},
 			shouldError: false,
+			status:      model.RunStatusSucceeded,
 		},
 		{
 			name: "Test TimeOut Command",
@@ -57,6 +64,7 @@ func TestShellJob_Run(t *testing.T) {
 				errOutput: make(chan string, 100),
 			},
 			shouldError: true,
+			status:      model.RunStatusTimedOut,
 		},
 		{
 			name: "Test Non-existent Command",
@@ -69,6 +77,7 @@ func TestShellJob_Run(t *testing.T) {
 				errOutput: make(chan string, 100),
 			},
 			shouldError: true,
+			status:      model.RunStatusFailed,
 		},
 	}
 	for _, tt := range tests {
@@ -80,15 +89,25 @@ func TestShellJob_Run(t *testing.T) {
 				Timeout:   tt.fields.timeOut,
 				output:    tt.fields.output,
 				errOutput: tt.fields.errOutput,
+				// 测试在 scheduler 目录下运行，工作目录指向项目根目录
+				workDir:  "..",
+				userName: ".",
 			}
-			s.Run(context.Background())
+			rs := NewRunState("run_test", "task_test", model.TriggerManual)
+			s.Run(WithRunState(context.Background(), rs))
+
+			assert.Equal(t, tt.status, rs.Status(), "run status mismatch")
 
 			if tt.shouldError {
 				// 预期错误，应该从 ErrOutput 收到数据
 				select {
 				case errOutput := <-s.ErrOutput():
 					t.Logf("Received expected error: %s", errOutput)
-					assert.Contains(t, errOutput, "error", "Error output should contain the word 'error'")
+					if tt.status == model.RunStatusTimedOut {
+						assert.Contains(t, errOutput, "timed out", "Timeout should be reported distinctly")
+					} else {
+						assert.Contains(t, errOutput, "error", "Error output should contain the word 'error'")
+					}
 				case <-s.Output():
 					t.Errorf("Expected error output, but received standard output")
 				}
@@ -105,3 +124,48 @@ func TestShellJob_Run(t *testing.T) {
 		})
 	}
 }
+
+// 超时后整个进程组都应被终止，包括 Shell 在后台启动的子进程
+func TestShellJob_KillProcessGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{name: "terminate", command: "sleep 30 & echo $! > child.pid; wait"},
+		{name: "kill after grace period", command: "trap '' TERM; sleep 30 & echo $! > child.pid; wait"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := NewShellJob(true, 200*time.Millisecond, dir, "tester", tt.command)
+			rs := NewRunState("run_test", "task_test", model.TriggerManual)
+			ctx := WithKillGracePeriod(WithRunState(context.Background(), rs), 300*time.Millisecond)
+
+			start := time.Now()
+			s.Run(ctx)
+			assert.Less(t, time.Since(start), 3*time.Second)
+			assert.Equal(t, model.RunStatusTimedOut, rs.Status())
+			assert.Contains(t, readChannel(s.ErrOutput()), "Command timed out after 200ms")
+
+			pid, err := os.ReadFile(filepath.Join(dir, "tester", "child.pid"))
+			assert.NoError(t, err)
+			assert.Eventually(t, func() bool {
+				stat, err := os.ReadFile(filepath.Join("/proc", strings.TrimSpace(string(pid)), "stat"))
+				// 进程已被回收，或已退出只剩僵尸进程
+				return err != nil || strings.Contains(string(stat), ") Z ")
+			}, 2*time.Second, 50*time.Millisecond)
+		})
+	}
+}
+
+func TestShellJob_ResourceUsage(t *testing.T) {
+	s := NewShellJob(true, 5*time.Second, t.TempDir(), "tester", "i=0; while [ $i -lt 50000 ]; do i=$((i+1)); done")
+	rs := NewRunState("run_test", "task_test", model.TriggerManual)
+
+	s.Run(WithRunState(context.Background(), rs))
+	assert.Equal(t, model.RunStatusSucceeded, rs.Status())
+
+	usage := rs.Usage()
+	assert.Greater(t, usage.UserCPUMs+usage.SystemCPUMs, int64(0))
+	assert.Greater(t, usage.MaxRSSKB, int64(0))
+}
@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultOutputLimit 单次执行每路输出在内存和任务日志中保留的默认大小
+	DefaultOutputLimit = 32 << 10
+
+	// truncatedMarkerReserve 为截断提示预留的字节数，保证截断后的输出不超过上限
+	truncatedMarkerReserve = 64
+
+	// DefaultOutputRetention 溢出文件的默认保留时间
+	DefaultOutputRetention = 7 * 24 * time.Hour
+	// outputSweepInterval 清理过期溢出文件的间隔
+	outputSweepInterval = time.Hour
+	// spoolFileSuffix 溢出文件名的后缀，清理时只删除该后缀的文件
+	spoolFileSuffix = ".log.gz"
+)
+
+type outputLimitsKey struct{}
+
+// outputLimits 输出的内存上限和溢出文件目录
+type outputLimits struct {
+	limit int
+	dir   string // 为空时超出部分直接丢弃
+}
+
+// WithOutputLimits 设置每路输出保留的大小以及超出时完整输出的保存目录
+func WithOutputLimits(ctx context.Context, limit int, dir string) context.Context {
+	return context.WithValue(ctx, outputLimitsKey{}, outputLimits{limit: limit, dir: dir})
+}
+
+func outputLimitsFromContext(ctx context.Context) outputLimits {
+	l, _ := ctx.Value(outputLimitsKey{}).(outputLimits)
+	if l.limit <= 0 {
+		l.limit = DefaultOutputLimit
+	}
+	return l
+}
+
+// cappedBuffer 只保留输出开头和结尾的部分，中间超出上限的内容丢弃，String 返回的长度不超过 limit
+type cappedBuffer struct {
+	headLimit int
+	tailLimit int
+	head      []byte
+	tail      []byte
+	total     int64
+}
+
+func newCappedBuffer(limit int) *cappedBuffer {
+	limit = max(limit-truncatedMarkerReserve, 2)
+	return &cappedBuffer{headLimit: limit / 2, tailLimit: limit - limit/2}
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	rest := p
+	if n := min(b.headLimit-len(b.head), len(rest)); n > 0 {
+		b.head = append(b.head, rest[:n]...)
+		rest = rest[n:]
+	}
+	if len(rest) >= b.tailLimit {
+		b.tail = append(b.tail[:0], rest[len(rest)-b.tailLimit:]...)
+		return len(p), nil
+	}
+	b.tail = append(b.tail, rest...)
+	// 攒够一倍再整体前移，避免每次写入都复制
+	if len(b.tail) > 2*b.tailLimit {
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-b.tailLimit:]...)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	tail := b.tail
+	if len(tail) > b.tailLimit {
+		tail = tail[len(tail)-b.tailLimit:]
+	}
+	dropped := b.total - int64(len(b.head)) - int64(len(tail))
+	if dropped <= 0 {
+		return string(b.head) + string(tail)
+	}
+
+	// 截断处不拆开 UTF-8 多字节字符
+	head := b.head
+	for i := 0; i < utf8.UTFMax-1 && len(head) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(head); r != utf8.RuneError || size != 1 {
+			break
+		}
+		head = head[:len(head)-1]
+	}
+	for i := 0; i < utf8.UTFMax-1 && len(tail) > 0 && !utf8.RuneStart(tail[0]); i++ {
+		tail = tail[1:]
+	}
+	dropped = b.total - int64(len(head)) - int64(len(tail))
+	return fmt.Sprintf("%s\n... [%d bytes truncated] ...\n%s", head, dropped, tail)
+}
+
+// truncateOutput 将超过上限的输出截断为开头和结尾
+func truncateOutput(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	b := newCappedBuffer(limit)
+	_, _ = b.Write([]byte(s))
+	return b.String()
+}
+
+// outputSpool 记录一次执行逐行上报的输出，某一路超过上限后将该路的完整输出压缩写入文件
+type outputSpool struct {
+	mu      sync.Mutex
+	dir     string
+	runID   string
+	limit   int
+	streams map[string]*spoolStream
+}
+
+type spoolStream struct {
+	pending bytes.Buffer // 未超过上限前暂存在内存中的输出
+	name    string       // 溢出文件名，相对于输出目录
+	file    *os.File
+	gz      *gzip.Writer
+	err     error
+}
+
+func newOutputSpool(dir, runID string, limit int) *outputSpool {
+	// 与 cappedBuffer 一致，达到扣除截断提示后的大小即开始写文件，保证被截断的输出都有完整文件
+	limit = max(limit-truncatedMarkerReserve, 2)
+	return &outputSpool{dir: dir, runID: runID, limit: limit, streams: make(map[string]*spoolStream)}
+}
+
+func (s *outputSpool) stream(name string) *spoolStream {
+	st, ok := s.streams[name]
+	if !ok {
+		st = &spoolStream{}
+		s.streams[name] = st
+	}
+	return st
+}
+
+// writeLine 追加一行已打码的输出
+func (s *outputSpool) writeLine(stream, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stream(stream)
+	if st.err != nil {
+		return
+	}
+	if st.gz == nil {
+		st.pending.WriteString(line)
+		st.pending.WriteByte('\n')
+		if st.pending.Len() <= s.limit {
+			return
+		}
+		if st.err = s.open(stream, st); st.err != nil {
+			return
+		}
+		_, st.err = st.pending.WriteTo(st.gz)
+		st.pending = bytes.Buffer{}
+		return
+	}
+	_, err := st.gz.Write([]byte(line + "\n"))
+	st.err = err
+}
+
+func (s *outputSpool) open(stream string, st *spoolStream) error {
+	name := fmt.Sprintf("%s-%s%s", s.runID, stream, spoolFileSuffix)
+	f, err := os.OpenFile(filepath.Join(s.dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	st.name, st.file, st.gz = name, f, gzip.NewWriter(f)
+	return nil
+}
+
+// finish 结束某一路输出并返回溢出文件名，没有逐行上报但最终输出超过上限时将 output 整体写入文件
+func (s *outputSpool) finish(stream, output string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stream(stream)
+	if st.gz == nil && st.err == nil && len(output) > s.limit {
+		if st.err = s.open(stream, st); st.err == nil {
+			_, st.err = st.gz.Write([]byte(output))
+		}
+	}
+	if st.gz == nil {
+		return "", st.err
+	}
+	err := st.err
+	if cerr := st.gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := st.file.Close(); err == nil {
+		err = cerr
+	}
+	st.gz = nil
+	if err != nil {
+		_ = os.Remove(filepath.Join(s.dir, st.name))
+		return "", err
+	}
+	return st.name, nil
+}
+
+// removeExpiredSpools 删除输出目录中最后修改时间早于 before 的溢出文件，返回删除的文件数
+// 执行中的溢出文件会持续写入，修改时间不会早于 before
+func removeExpiredSpools(dir string, before time.Time) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
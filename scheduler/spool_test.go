@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	b := newCappedBuffer(256)
+	_, _ = b.Write([]byte("short"))
+	assert.Equal(t, "short", b.String())
+
+	for i := 0; i < 1000; i++ {
+		_, _ = b.Write([]byte("0123456789"))
+	}
+	_, _ = b.Write([]byte("END"))
+	s := b.String()
+	assert.LessOrEqual(t, len(s), 256)
+	assert.True(t, strings.HasPrefix(s, "short0123"))
+	assert.True(t, strings.HasSuffix(s, "789END"))
+	assert.Contains(t, s, "bytes truncated")
+
+	assert.Equal(t, "abc", truncateOutput("abc", 256))
+	assert.LessOrEqual(t, len(truncateOutput(strings.Repeat("中", 1000), 256)), 256)
+}
+
+func TestBaseExecutor_SpoolOutput(t *testing.T) {
+	dir := t.TempDir()
+	job := NewShellJob(true, 5*time.Second, t.TempDir(), "tester", "seq 1 20000")
+	task := NewTask("task_1", "spool", "tester", "* * * * *", "", job)
+	rs := NewRunState("run_spool", "task_1", model.TriggerManual)
+	ctx := WithOutputLimits(WithRunState(context.Background(), rs), 1024, dir)
+
+	result := BaseExecutor(ctx, task)
+	assert.Equal(t, model.RunStatusSucceeded, result.Status)
+	assert.LessOrEqual(t, len(result.Output), 1024)
+	assert.True(t, strings.HasPrefix(result.Output, "1\n2\n"))
+	assert.True(t, strings.HasSuffix(result.Output, "19999\n20000\n"))
+	assert.Equal(t, "run_spool-stdout.log.gz", result.OutputFile)
+	assert.Empty(t, result.ErrOutputFile)
+
+	f, err := os.Open(filepath.Join(dir, result.OutputFile))
+	assert.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	full, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(full), "\n"), "\n")
+	assert.Len(t, lines, 20000)
+	assert.Equal(t, "20000", lines[len(lines)-1])
+}
+
+func TestLineWriter_LongLine(t *testing.T) {
+	var lines []string
+	w := newLineWriter(io.Discard, func(line string) { lines = append(lines, line) })
+
+	// 不换行的输出超过单行上限后按行推送，不会一直留在内存中
+	chunk := []byte(strings.Repeat("x", 1000))
+	for i := 0; i < 200; i++ {
+		_, _ = w.Write(chunk)
+	}
+	assert.Len(t, lines, 3)
+	assert.Len(t, lines[0], maxOutputLineSize)
+	assert.Less(t, len(w.partial), maxOutputLineSize)
+	w.Flush()
+	assert.Equal(t, 200000, len(strings.Join(lines, "")))
+
+	b := newOutputBroadcaster()
+	b.publish(OutputLine{Stream: OutputStreamStdout, Line: lines[0]})
+	ch, _ := b.subscribe()
+	line := <-ch
+	assert.Less(t, len(line.Line), outputHistoryLineSize+64)
+	assert.Contains(t, line.Line, "bytes truncated")
+}
+
+func TestRemoveExpiredSpools(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	for _, name := range []string{"run_1-stdout.log.gz", "run_2-stdout.log.gz", "notes.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600))
+	}
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "run_1-stdout.log.gz"), old, old))
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "notes.txt"), old, old))
+
+	// 只删除过期的溢出文件，其他文件不受影响
+	removed, err := removeExpiredSpools(dir, time.Now().Add(-DefaultOutputRetention))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = os.Stat(filepath.Join(dir, "run_1-stdout.log.gz"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "run_2-stdout.log.gz"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+	assert.NoError(t, err)
+}
@@ -9,26 +9,116 @@ import (
 type Task struct {
 	id            string
 	taskName      string // 任务名称
-	scheduledTime string // cron表达式
+	scheduledTime string // 调度描述，含义取决于 scheduleType
+	scheduleType  string // 调度方式(cron/at/every)
+	timeZone      string // IANA 时区名，为空表示服务器本地时区
 	ownerName     string // 拥有者
 	description   string // 描述
+	status        string // 任务状态(active/paused)
 	f             Job
+
+	retryPolicy       *RetryPolicy // 重试策略，为空表示不重试
+	concurrencyPolicy string       // 重叠执行的并发策略
+	rerunInterrupted  bool         // 服务异常退出导致执行中断时，重启后是否重新执行一次
+	misfirePolicy     string       // 错过触发时间时的处理策略
+	misfireLimit      int          // run_all 策略最多补执行的次数
+
+	upstreams   []string // 上游任务ID，非空时任务由工作流触发而不参与 cron 调度
+	triggerRule string   // 上游完成后触发本任务的规则
+}
+
+// TaskOption 任务的可选配置
+type TaskOption func(t *Task)
+
+// WithScheduleType 设置任务的调度方式，为空时使用 cron 表达式
+func WithScheduleType(scheduleType string) TaskOption {
+	return func(t *Task) {
+		if scheduleType != "" {
+			t.scheduleType = scheduleType
+		}
+	}
+}
+
+// WithTimeZone 设置任务调度使用的 IANA 时区
+func WithTimeZone(timeZone string) TaskOption {
+	return func(t *Task) {
+		t.timeZone = timeZone
+	}
+}
+
+// WithConcurrencyPolicy 设置任务重叠执行时的并发策略，为空时允许并行
+func WithConcurrencyPolicy(policy string) TaskOption {
+	return func(t *Task) {
+		if policy != "" {
+			t.concurrencyPolicy = policy
+		}
+	}
+}
+
+// WithRerunInterrupted 设置服务异常退出导致执行中断时，重启后是否重新执行一次
+func WithRerunInterrupted(rerun bool) TaskOption {
+	return func(t *Task) {
+		t.rerunInterrupted = rerun
+	}
+}
+
+// WithMisfirePolicy 设置错过触发时间时的处理策略，为空时跳过；limit 为 run_all 策略最多补执行的次数，为0时使用默认值
+func WithMisfirePolicy(policy string, limit int) TaskOption {
+	return func(t *Task) {
+		if policy != "" {
+			t.misfirePolicy = policy
+		}
+		t.misfireLimit = limit
+	}
+}
+
+// WithUpstreams 设置任务依赖的上游任务
+func WithUpstreams(upstreams []string) TaskOption {
+	return func(t *Task) {
+		t.upstreams = upstreams
+	}
+}
+
+// WithTriggerRule 设置上游完成后触发本任务的规则，为空时要求上游全部成功
+func WithTriggerRule(rule string) TaskOption {
+	return func(t *Task) {
+		if rule != "" {
+			t.triggerRule = rule
+		}
+	}
+}
+
+// WithRetryPolicy 设置任务的重试策略
+func WithRetryPolicy(p *RetryPolicy) TaskOption {
+	return func(t *Task) {
+		t.retryPolicy = p
+	}
 }
 
 func (t *Task) String() string {
-	return fmt.Sprintf("Task{id: %v, taskName: %s, scheduledTime: %s, ownerName: %s, description: %s, job: %v}",
-		t.id, t.taskName, t.scheduledTime, t.ownerName, t.description, t.f)
+	return fmt.Sprintf("Task{id: %v, taskName: %s, scheduleType: %s, scheduledTime: %s, timeZone: %s, ownerName: %s, description: %s, status: %s, job: %v}",
+		t.id, t.taskName, t.scheduleType, t.scheduledTime, t.timeZone, t.ownerName, t.description, t.status, t.f)
 }
 
-func NewTask(id, taskName, ownerName, scheduledTime, description string, job Job) Task {
-	return Task{
+func NewTask(id, taskName, ownerName, scheduledTime, description string, job Job, opts ...TaskOption) Task {
+	t := Task{
 		id:            id,
 		taskName:      taskName,
 		scheduledTime: scheduledTime,
+		scheduleType:  ScheduleTypeCron,
 		ownerName:     ownerName,
 		description:   description,
+		status:        model.TaskStatusActive,
 		f:             job,
+
+		concurrencyPolicy: ConcurrencyAllow,
+		misfirePolicy:     MisfireSkip,
+		triggerRule:       TriggerRuleAllSuccess,
+	}
+	for _, opt := range opts {
+		opt(&t)
 	}
+	return t
 }
 
 func NewTaskFromModel(taskInfo *model.TaskInfo) (Task, error) {
@@ -42,30 +132,103 @@ func NewTaskFromModel(taskInfo *model.TaskInfo) (Task, error) {
 		return Task{}, err
 	}
 
+	retryPolicy, err := retryPolicyFromJson(taskInfo.RetryPolicy)
+	if err != nil {
+		return Task{}, fmt.Errorf("unmarshal retry policy failed: %w", err)
+	}
+
+	scheduleType := taskInfo.ScheduleType
+	if scheduleType == "" {
+		scheduleType = ScheduleTypeCron
+	}
+
+	concurrencyPolicy := taskInfo.Concurrency
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = ConcurrencyAllow
+	}
+
+	misfirePolicy := taskInfo.MisfirePolicy
+	if misfirePolicy == "" {
+		misfirePolicy = MisfireSkip
+	}
+
+	status := taskInfo.Status
+	if status == "" {
+		status = model.TaskStatusActive
+	}
+
+	upstreams, err := upstreamsFromJson(taskInfo.Upstreams)
+	if err != nil {
+		return Task{}, fmt.Errorf("unmarshal upstream task ids failed: %w", err)
+	}
+
+	triggerRule := taskInfo.TriggerRule
+	if triggerRule == "" {
+		triggerRule = TriggerRuleAllSuccess
+	}
+
 	return Task{
 		id:            taskInfo.TaskId,
 		taskName:      taskInfo.TaskName,
 		scheduledTime: taskInfo.ScheduledTime,
+		scheduleType:  scheduleType,
+		timeZone:      taskInfo.TimeZone,
 		ownerName:     taskInfo.OwnerName,
 		description:   taskInfo.Description,
+		status:        status,
 		f:             j,
+		retryPolicy:   retryPolicy,
+
+		concurrencyPolicy: concurrencyPolicy,
+		rerunInterrupted:  taskInfo.RerunInterrupted,
+		misfirePolicy:     misfirePolicy,
+		misfireLimit:      taskInfo.MisfireLimit,
+		upstreams:         upstreams,
+		triggerRule:       triggerRule,
 	}, nil
 }
 
-func GetJob(jobType string) (Job, error) {
-	switch jobType {
-	case ShellJobType:
-		return new(ShellJob), nil
-	default:
-		return nil, fmt.Errorf("job type unknown")
+// jobValidator 添加或更新任务前需要校验自身配置的 Job
+type jobValidator interface {
+	Validate() error
+}
+
+// validate 校验任务的可选配置
+func (t *Task) validate() error {
+	if v, ok := t.f.(jobValidator); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
 	}
+	if t.retryPolicy != nil {
+		if err := t.retryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateConcurrencyPolicy(t.concurrencyPolicy); err != nil {
+		return err
+	}
+	if err := validateMisfirePolicy(t.misfirePolicy, t.misfireLimit); err != nil {
+		return err
+	}
+	return validateUpstreams(t.id, t.upstreams, t.triggerRule)
 }
 
 type TaskResult struct {
-	StartTime time.Time
-	EndTime   time.Time
-	Output    string
-	ErrOutput string
+	RunID         string // 单次执行ID
+	ParentRunID   string // 重试时指向首次执行的运行ID，恢复执行时指向被中断的运行ID
+	Attempt       int    // 第几次尝试
+	WorkflowRunID string // 所属工作流运行ID
+	Trigger       string // 触发来源
+	Status        string // 执行状态，见 model.RunStatusSucceeded 等
+	ExitCode      int    // 进程退出码，未能获取时为 -1
+	StartTime     time.Time
+	EndTime       time.Time
+	Output        string
+	ErrOutput     string
+	Usage         model.ResourceUsage // 资源使用统计
+	OutputFile    string              // 输出超过上限时完整输出的文件名
+	ErrOutputFile string              // 错误输出超过上限时完整输出的文件名
 }
 
 func (t *Task) GetID() string {
@@ -80,6 +243,14 @@ func (t *Task) GetScheduledTime() string {
 	return t.scheduledTime
 }
 
+func (t *Task) GetScheduleType() string {
+	return t.scheduleType
+}
+
+func (t *Task) GetTimeZone() string {
+	return t.timeZone
+}
+
 func (t *Task) GetOwnerName() string {
 	return t.ownerName
 }
@@ -88,6 +259,51 @@ func (t *Task) GetDescription() string {
 	return t.description
 }
 
+func (t *Task) GetStatus() string {
+	return t.status
+}
+
+func (t *Task) IsPaused() bool {
+	return t.status == model.TaskStatusPaused
+}
+
+func (t *Task) IsCompleted() bool {
+	return t.status == model.TaskStatusCompleted
+}
+
+func (t *Task) GetRetryPolicy() *RetryPolicy {
+	return t.retryPolicy
+}
+
+func (t *Task) GetConcurrencyPolicy() string {
+	return t.concurrencyPolicy
+}
+
+func (t *Task) GetRerunInterrupted() bool {
+	return t.rerunInterrupted
+}
+
+func (t *Task) GetMisfirePolicy() string {
+	return t.misfirePolicy
+}
+
+func (t *Task) GetMisfireLimit() int {
+	return t.misfireLimit
+}
+
+func (t *Task) GetUpstreams() []string {
+	return t.upstreams
+}
+
+func (t *Task) GetTriggerRule() string {
+	return t.triggerRule
+}
+
+// IsDownstream 任务是否依赖上游任务，依赖上游的任务只由工作流触发
+func (t *Task) IsDownstream() bool {
+	return len(t.upstreams) > 0
+}
+
 func (t *Task) GetJob() Job {
 	return t.f
 }
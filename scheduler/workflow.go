@@ -0,0 +1,355 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+)
+
+const (
+	WorkflowRunIDPrefix = "wf_"
+)
+
+// 上游任务结束后触发下游任务的规则
+const (
+	TriggerRuleAllSuccess = "all_success" // 上游全部成功
+	TriggerRuleAnyFailed  = "any_failed"  // 至少一个上游失败
+	TriggerRuleAllDone    = "all_done"    // 上游全部结束，不论成败
+)
+
+func validateTriggerRule(rule string) error {
+	switch rule {
+	case TriggerRuleAllSuccess, TriggerRuleAnyFailed, TriggerRuleAllDone:
+		return nil
+	default:
+		return fmt.Errorf("trigger rule %q unknown", rule)
+	}
+}
+
+// validateUpstreams 校验上游任务列表本身是否合法，是否成环由 workflowGraph 检查
+func validateUpstreams(taskID string, upstreams []string, rule string) error {
+	if err := validateTriggerRule(rule); err != nil {
+		return err
+	}
+	for i, up := range upstreams {
+		if up == "" {
+			return fmt.Errorf("upstream task id cannot be empty")
+		}
+		if up == taskID {
+			return fmt.Errorf("task %s cannot depend on itself", taskID)
+		}
+		if slices.Contains(upstreams[:i], up) {
+			return fmt.Errorf("upstream task id %s is duplicated", up)
+		}
+	}
+	return nil
+}
+
+// isFailedRunStatus 执行是否以失败告终，被跳过的执行不算失败
+func isFailedRunStatus(status string) bool {
+	switch status {
+	case model.RunStatusFailed, model.RunStatusTimedOut, model.RunStatusPanicked, model.RunStatusCancelled,
+		model.RunStatusLimitExceeded, model.RunStatusInterrupted:
+		return true
+	}
+	return false
+}
+
+// triggerRuleSatisfied 判断上游的最终状态是否满足下游的触发规则
+func triggerRuleSatisfied(rule string, upstreamStatuses []string) bool {
+	switch rule {
+	case TriggerRuleAllDone:
+		return true
+	case TriggerRuleAnyFailed:
+		return slices.ContainsFunc(upstreamStatuses, isFailedRunStatus)
+	default:
+		for _, status := range upstreamStatuses {
+			if status != model.RunStatusSucceeded {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func upstreamsToJson(upstreams []string) string {
+	if len(upstreams) == 0 {
+		return ""
+	}
+	res, _ := json.Marshal(upstreams)
+	return string(res)
+}
+
+func upstreamsFromJson(jsonStr string) ([]string, error) {
+	if jsonStr == "" {
+		return nil, nil
+	}
+	var upstreams []string
+	if err := json.Unmarshal([]byte(jsonStr), &upstreams); err != nil {
+		return nil, err
+	}
+	return upstreams, nil
+}
+
+// workflowGraph 任务之间的依赖关系
+type workflowGraph struct {
+	upstreams   map[string][]string // 任务ID -> 上游任务ID
+	downstreams map[string][]string // 任务ID -> 下游任务ID
+}
+
+func newWorkflowGraph() *workflowGraph {
+	return &workflowGraph{
+		upstreams:   make(map[string][]string),
+		downstreams: make(map[string][]string),
+	}
+}
+
+// set 替换任务的上游依赖
+func (g *workflowGraph) set(taskID string, upstreams []string) {
+	for _, up := range g.upstreams[taskID] {
+		g.downstreams[up] = slices.DeleteFunc(g.downstreams[up], func(id string) bool { return id == taskID })
+		if len(g.downstreams[up]) == 0 {
+			delete(g.downstreams, up)
+		}
+	}
+	delete(g.upstreams, taskID)
+
+	if len(upstreams) == 0 {
+		return
+	}
+	g.upstreams[taskID] = slices.Clone(upstreams)
+	for _, up := range upstreams {
+		g.downstreams[up] = append(g.downstreams[up], taskID)
+	}
+}
+
+// checkCycle 检查把任务的上游设置为 upstreams 后是否会成环
+func (g *workflowGraph) checkCycle(taskID string, upstreams []string) error {
+	visited := make(map[string]bool)
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		path = append(path, id)
+		if id == taskID {
+			return fmt.Errorf("dependency cycle detected: %v", path)
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		for _, up := range g.upstreams[id] {
+			if err := visit(up, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, up := range upstreams {
+		if err := visit(up, []string{taskID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reachable 返回从 root 出发沿下游方向可达的所有任务(含 root)
+func (g *workflowGraph) reachable(root string) map[string]bool {
+	nodes := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, down := range g.downstreams[id] {
+			if !nodes[down] {
+				nodes[down] = true
+				queue = append(queue, down)
+			}
+		}
+	}
+	return nodes
+}
+
+// workflowRun 一次工作流运行，从被定时或手动触发的根任务开始，包含其下游可达的所有任务
+type workflowRun struct {
+	nodes      map[string]bool   // 本次运行包含的任务
+	results    map[string]string // 已结束任务的最终状态
+	dispatched map[string]bool   // 已触发或已跳过的任务
+	inFlight   int               // 已触发但尚未结束的任务数
+}
+
+// readyNode 上游已全部结束、等待按触发规则决定是否执行的任务
+type readyNode struct {
+	taskID           string
+	upstreamStatuses []string
+}
+
+// workflowTracker 维护任务依赖图和进行中的工作流运行
+type workflowTracker struct {
+	mu    sync.Mutex
+	graph *workflowGraph
+	runs  map[string]*workflowRun
+}
+
+func newWorkflowTracker() *workflowTracker {
+	return &workflowTracker{
+		graph: newWorkflowGraph(),
+		runs:  make(map[string]*workflowRun),
+	}
+}
+
+func (w *workflowTracker) setUpstreams(taskID string, upstreams []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.graph.set(taskID, upstreams)
+}
+
+func (w *workflowTracker) checkCycle(taskID string, upstreams []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.graph.checkCycle(taskID, upstreams)
+}
+
+func (w *workflowTracker) downstreams(taskID string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.graph.downstreams[taskID])
+}
+
+// begin 以 root 为根开启一次工作流运行，root 没有下游任务时不开启并返回 false
+func (w *workflowTracker) begin(runID, root string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.graph.downstreams[root]) == 0 {
+		return false
+	}
+	w.runs[runID] = &workflowRun{
+		nodes:      w.graph.reachable(root),
+		results:    make(map[string]string),
+		dispatched: map[string]bool{root: true},
+		inFlight:   1,
+	}
+	return true
+}
+
+// complete 记录任务在本次运行中的最终状态，返回因此而就绪的下游任务并将其计入进行中
+// 只有属于本次运行的上游才参与判断，所有任务结束后运行被移除
+func (w *workflowTracker) complete(runID, taskID, status string) []readyNode {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	run, ok := w.runs[runID]
+	if !ok {
+		return nil
+	}
+	run.results[taskID] = status
+	run.inFlight--
+
+	var ready []readyNode
+	for _, down := range w.graph.downstreams[taskID] {
+		if !run.nodes[down] || run.dispatched[down] {
+			continue
+		}
+		node := readyNode{taskID: down}
+		done := true
+		for _, up := range w.graph.upstreams[down] {
+			if !run.nodes[up] {
+				continue
+			}
+			upStatus, finished := run.results[up]
+			if !finished {
+				done = false
+				break
+			}
+			node.upstreamStatuses = append(node.upstreamStatuses, upStatus)
+		}
+		if !done {
+			continue
+		}
+		run.dispatched[down] = true
+		run.inFlight++
+		ready = append(ready, node)
+	}
+
+	if run.inFlight <= 0 {
+		delete(w.runs, runID)
+	}
+	return ready
+}
+
+// startWorkflow 任务被定时或手动触发时，如果存在下游任务则开启一次新的工作流运行并返回其ID
+func (s *CronScheduler) startWorkflow(t Task) string {
+	runID := s.runIDGenerator.Generate(WorkflowRunIDPrefix)
+	if !s.workflows.begin(runID, t.GetID()) {
+		return ""
+	}
+	s.log.Infof("🔗 start workflow run %s from task %s", runID, t.GetID())
+	return runID
+}
+
+// advanceWorkflow 任务结束后推进工作流，按触发规则执行或跳过已就绪的下游任务
+func (s *CronScheduler) advanceWorkflow(workflowRunID, ownerName, taskID, status string) {
+	for _, node := range s.workflows.complete(workflowRunID, taskID, status) {
+		s.dispatchWorkflowNode(workflowRunID, ownerName, node)
+	}
+}
+
+func (s *CronScheduler) dispatchWorkflowNode(workflowRunID, ownerName string, node readyNode) {
+	info, err := s.taskInfoDao.GetTaskInfo(ownerName, node.taskID)
+	if err != nil {
+		s.log.Errorf("workflow run %s: get downstream task (user_name=%s and task_id=%s) error: %s", workflowRunID, ownerName, node.taskID, err)
+		s.advanceWorkflow(workflowRunID, ownerName, node.taskID, model.RunStatusSkipped)
+		return
+	}
+	task, err := NewTaskFromModel(info)
+	if err != nil {
+		s.log.Errorf("workflow run %s: new task from model failed: %s", workflowRunID, err)
+		s.advanceWorkflow(workflowRunID, ownerName, node.taskID, model.RunStatusSkipped)
+		return
+	}
+
+	switch {
+	case task.IsPaused():
+		s.skipWorkflowNode(workflowRunID, task, "skipped: task is paused")
+		return
+	case !triggerRuleSatisfied(task.GetTriggerRule(), node.upstreamStatuses):
+		s.skipWorkflowNode(workflowRunID, task, fmt.Sprintf("skipped: trigger rule %s not satisfied, upstream statuses: %v",
+			task.GetTriggerRule(), node.upstreamStatuses))
+		return
+	}
+
+	e, err := s.newRun(task, model.TriggerWorkflow, workflowRunID)
+	if err != nil {
+		s.log.Warnf("workflow run %s: task %s is not triggered: %v", workflowRunID, task.GetID(), err)
+		return
+	}
+	s.log.Infof("🔗 workflow run %s: trigger task %s (run %s)", workflowRunID, task.GetID(), e.first.RunID)
+	s.dispatch(e)
+}
+
+// skipWorkflowNode 记录未满足触发条件的节点，使工作流中每个节点都有对应的任务日志
+func (s *CronScheduler) skipWorkflowNode(workflowRunID string, t Task, reason string) {
+	now := time.Now()
+	taskLog := model.TaskLog{
+		TaskId:        t.GetID(),
+		RunId:         s.runIDGenerator.Generate(RunIDPrefix),
+		Attempt:       1,
+		Name:          t.GetTaskName(),
+		Content:       t.GetJob().Content(),
+		ErrOutput:     reason,
+		Status:        model.RunStatusSkipped,
+		ExitCode:      -1,
+		Trigger:       model.TriggerWorkflow,
+		WorkflowRunId: workflowRunID,
+		StartTime:     now,
+		EndTime:       now,
+	}
+	s.taskLogWriter.WriteResult(t.GetOwnerName(), taskLog)
+	s.log.Infof("🔗 workflow run %s: %s, task %s", workflowRunID, reason, t.GetID())
+
+	s.advanceWorkflow(workflowRunID, t.GetOwnerName(), t.GetID(), model.RunStatusSkipped)
+}
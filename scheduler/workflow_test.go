@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowGraph_CheckCycle(t *testing.T) {
+	g := newWorkflowGraph()
+	// dump -> compress -> upload
+	g.set("compress", []string{"dump"})
+	g.set("upload", []string{"compress"})
+
+	assert.NoError(t, g.checkCycle("notify", []string{"upload", "dump"}))
+	assert.Error(t, g.checkCycle("dump", []string{"upload"}), "dump <- upload <- compress <- dump")
+	assert.Error(t, g.checkCycle("compress", []string{"upload"}))
+
+	// 修改上游后旧的依赖边被移除
+	g.set("upload", []string{"dump"})
+	assert.NoError(t, g.checkCycle("compress", []string{"upload"}))
+	assert.Equal(t, []string{"compress", "upload"}, g.downstreams["dump"])
+	assert.Empty(t, g.downstreams["compress"])
+}
+
+func TestTriggerRuleSatisfied(t *testing.T) {
+	succeeded := []string{model.RunStatusSucceeded, model.RunStatusSucceeded}
+	failed := []string{model.RunStatusSucceeded, model.RunStatusTimedOut}
+	skipped := []string{model.RunStatusSucceeded, model.RunStatusSkipped}
+
+	assert.True(t, triggerRuleSatisfied(TriggerRuleAllSuccess, succeeded))
+	assert.False(t, triggerRuleSatisfied(TriggerRuleAllSuccess, failed))
+	assert.False(t, triggerRuleSatisfied(TriggerRuleAllSuccess, skipped))
+
+	assert.False(t, triggerRuleSatisfied(TriggerRuleAnyFailed, succeeded))
+	assert.True(t, triggerRuleSatisfied(TriggerRuleAnyFailed, failed))
+	assert.False(t, triggerRuleSatisfied(TriggerRuleAnyFailed, skipped))
+	assert.True(t, triggerRuleSatisfied(TriggerRuleAnyFailed, []string{model.RunStatusLimitExceeded}))
+	assert.True(t, triggerRuleSatisfied(TriggerRuleAnyFailed, []string{model.RunStatusInterrupted}))
+
+	assert.True(t, triggerRuleSatisfied(TriggerRuleAllDone, failed))
+	assert.True(t, triggerRuleSatisfied(TriggerRuleAllDone, skipped))
+}
+
+func TestWorkflowTracker_Complete(t *testing.T) {
+	w := newWorkflowTracker()
+	// a -> b, a -> c, (b, c) -> d, e -> d
+	w.setUpstreams("b", []string{"a"})
+	w.setUpstreams("c", []string{"a"})
+	w.setUpstreams("d", []string{"b", "c", "e"})
+
+	assert.False(t, w.begin("wf_0", "d"), "task without downstreams does not start a workflow")
+	assert.True(t, w.begin("wf_1", "a"))
+
+	ready := w.complete("wf_1", "a", model.RunStatusSucceeded)
+	assert.ElementsMatch(t, []string{"b", "c"}, []string{ready[0].taskID, ready[1].taskID})
+
+	// d 等待同一次运行中的 b 和 c，e 不属于本次运行不参与判断
+	assert.Empty(t, w.complete("wf_1", "b", model.RunStatusFailed))
+	ready = w.complete("wf_1", "c", model.RunStatusSucceeded)
+	assert.Len(t, ready, 1)
+	assert.Equal(t, "d", ready[0].taskID)
+	assert.Equal(t, []string{model.RunStatusFailed, model.RunStatusSucceeded}, ready[0].upstreamStatuses)
+
+	assert.Empty(t, w.complete("wf_1", "d", model.RunStatusSkipped))
+	_, exists := w.runs["wf_1"]
+	assert.False(t, exists, "finished workflow run should be removed")
+}
+
+func TestValidateUpstreams(t *testing.T) {
+	assert.NoError(t, validateUpstreams("a", []string{"b", "c"}, TriggerRuleAllSuccess))
+	assert.Error(t, validateUpstreams("a", []string{"a"}, TriggerRuleAllSuccess))
+	assert.Error(t, validateUpstreams("a", []string{"b", "b"}, TriggerRuleAllSuccess))
+	assert.Error(t, validateUpstreams("a", []string{"b"}, "some_success"))
+}
@@ -0,0 +1,29 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCipher(t *testing.T) {
+	c, err := NewCipher("cVqXUPJOHazVKx4XyLid0itnVY5j1AqNHmjXeXfen7Y=")
+	assert.NoError(t, err)
+
+	ciphertext, err := c.Encrypt("p@ss", "alice/db_password")
+	assert.NoError(t, err)
+	assert.NotContains(t, ciphertext, "p@ss")
+
+	plaintext, err := c.Decrypt(ciphertext, "alice/db_password")
+	assert.NoError(t, err)
+	assert.Equal(t, "p@ss", plaintext)
+
+	// 复制到其他用户下的密文无法解密
+	_, err = c.Decrypt(ciphertext, "bob/db_password")
+	assert.Error(t, err)
+
+	_, err = NewCipher("c2hvcnQ=")
+	assert.Error(t, err)
+	_, err = NewCipher("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/chencheng8888/GoDo/config"
+	"github.com/chencheng8888/GoDo/dao"
+	"github.com/chencheng8888/GoDo/dao/model"
+	"github.com/google/wire"
+)
+
+var ProviderSet = wire.NewSet(NewStore)
+
+var ErrNotConfigured = errors.New("secret store not configured, set secret.key to the output of `openssl rand -base64 32`")
+
+var nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+// ValidateName 密钥名称只能包含字母、数字、下划线、点和中划线
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	return nil
+}
+
+// Store 按用户保存加密的密钥，任务通过名称引用，执行时解密注入
+// 未配置密钥时 cipher 为 nil，所有读写操作返回 ErrNotConfigured
+type Store struct {
+	cipher *Cipher
+	dao    *dao.UserSecretDao
+}
+
+// NewStore 未配置密钥时返回停用的 Store，密钥无效时返回错误
+func NewStore(cf *config.SecretConfig, secretDao *dao.UserSecretDao) (*Store, error) {
+	if cf == nil || cf.Key == "" {
+		return &Store{dao: secretDao}, nil
+	}
+	c, err := NewCipher(cf.Key)
+	if err != nil {
+		return nil, fmt.Errorf("init secret store failed: %w", err)
+	}
+	return &Store{cipher: c, dao: secretDao}, nil
+}
+
+// Enabled 是否配置了密钥
+func (s *Store) Enabled() bool {
+	return s.cipher != nil
+}
+
+// additionalData 把密文绑定到用户和名称，防止被复制到其他用户或其他名称下使用
+func additionalData(userName, name string) string {
+	return userName + "/" + name
+}
+
+// Set 保存或覆盖用户的密钥
+func (s *Store) Set(userName, name, value string) error {
+	if !s.Enabled() {
+		return ErrNotConfigured
+	}
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	ciphertext, err := s.cipher.Encrypt(value, additionalData(userName, name))
+	if err != nil {
+		return err
+	}
+	return s.dao.SaveSecret(&model.UserSecret{UserName: userName, Name: name, Value: ciphertext})
+}
+
+func (s *Store) Delete(userName, name string) error {
+	if !s.Enabled() {
+		return ErrNotConfigured
+	}
+	return s.dao.DeleteSecret(userName, name)
+}
+
+// List 列出用户的密钥，不包含密钥值
+func (s *Store) List(userName string) ([]model.UserSecret, error) {
+	if !s.Enabled() {
+		return nil, ErrNotConfigured
+	}
+	return s.dao.ListSecrets(userName)
+}
+
+// Missing 返回 names 中用户尚未保存的密钥
+func (s *Store) Missing(userName string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if !s.Enabled() {
+		return nil, ErrNotConfigured
+	}
+	secrets, err := s.dao.GetSecrets(userName, names)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, name := range names {
+		if !slices.ContainsFunc(secrets, func(secret model.UserSecret) bool { return secret.Name == name }) {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// Resolve 解密用户的密钥，任一密钥不存在或解密失败时返回错误
+func (s *Store) Resolve(userName string, names []string) (map[string]string, error) {
+	res := make(map[string]string, len(names))
+	if len(names) == 0 {
+		return res, nil
+	}
+	if !s.Enabled() {
+		return nil, ErrNotConfigured
+	}
+	secrets, err := s.dao.GetSecrets(userName, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		value, err := s.cipher.Decrypt(secret.Value, additionalData(userName, secret.Name))
+		if err != nil {
+			return nil, fmt.Errorf("decrypt secret %s failed: %w", secret.Name, err)
+		}
+		res[secret.Name] = value
+	}
+	for _, name := range names {
+		if _, ok := res[name]; !ok {
+			return nil, fmt.Errorf("secret %s not found", name)
+		}
+	}
+	return res, nil
+}